@@ -0,0 +1,64 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"xolog/ast"
+	"xolog/token"
+)
+
+// astWriters maps the formats of `xolog ast` to the functions that write them.
+var astWriters = map[string]func(w io.Writer, node ast.Node) error{
+	"sexpr": ast.WriteSexpr,
+	"tree":  ast.WriteTree,
+	"dot":   ast.WriteDOT,
+}
+
+// astCommand will implement `xolog ast`, printing the syntax tree of a script, or of stdin
+// when no script is given. Syntax errors are reported, and the partial tree is printed.
+func astCommand(args []string) {
+	flags := flag.NewFlagSet("ast", flag.ExitOnError)
+	format := flags.String("format", "sexpr", "output `format`: sexpr, tree or dot")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: xolog [flags] ast [ast flags] [script]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(64)
+	}
+	write, ok := astWriters[*format]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown ast format %q\n", *format)
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	var tokens []token.Token
+	collect := func(tok token.Token) { tokens = append(tokens, tok) }
+	if path := flags.Arg(0); path != "" && path != "-" {
+		scanFile(path, collect)
+	} else {
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		scanSource("<stdin>", string(src), collect)
+	}
+
+	out := bufio.NewWriter(os.Stdout)
+	err := write(out, parse(tokens))
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
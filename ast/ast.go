@@ -0,0 +1,175 @@
+package ast
+
+import "xolog/token"
+
+// Node is implemented by every node of the syntax tree.
+type Node interface {
+	// Pos returns the position of the first character of the node.
+	Pos() token.Position
+	// End returns the position just past the last character of the node.
+	End() token.Position
+}
+
+// Expr is implemented by every expression node.
+type Expr interface {
+	Node
+	// Accept calls the method of v for the concrete type of the expression, and returns its result.
+	Accept(v ExprVisitor) interface{}
+	exprNode()
+}
+
+// ExprVisitor has one method per expression node, for use with Expr.Accept.
+type ExprVisitor interface {
+	VisitAssignExpr(e *Assign) interface{}
+	VisitBadExpr(e *BadExpr) interface{}
+	VisitBinaryExpr(e *Binary) interface{}
+	VisitCallExpr(e *Call) interface{}
+	VisitGetExpr(e *Get) interface{}
+	VisitGroupingExpr(e *Grouping) interface{}
+	VisitLiteralExpr(e *Literal) interface{}
+	VisitLogicalExpr(e *Logical) interface{}
+	VisitSetExpr(e *Set) interface{}
+	VisitSuperExpr(e *Super) interface{}
+	VisitThisExpr(e *This) interface{}
+	VisitUnaryExpr(e *Unary) interface{}
+	VisitVariableExpr(e *Variable) interface{}
+}
+
+type (
+	// Assign is an assignment to a variable, as in name = value.
+	Assign struct {
+		Name  token.Token
+		Value Expr
+	}
+
+	// BadExpr is a placeholder for an expression with a syntax error.
+	BadExpr struct {
+		From token.Position
+		To   token.Position
+	}
+
+	// Binary is an arithmetic or comparison operator applied to two operands.
+	Binary struct {
+		Left     Expr
+		Operator token.Token
+		Right    Expr
+	}
+
+	// Call is a call of Callee with Arguments. Paren is the closing parenthesis.
+	Call struct {
+		Callee    Expr
+		Paren     token.Token
+		Arguments []Expr
+	}
+
+	// Get is a property access, as in object.name.
+	Get struct {
+		Object Expr
+		Name   token.Token
+	}
+
+	// Grouping is an expression in parentheses.
+	Grouping struct {
+		Lparen     token.Token
+		Expression Expr
+		Rparen     token.Token
+	}
+
+	// Literal is a number, string, boolean or nil. Value is the literal of Token.
+	Literal struct {
+		Token token.Token
+		Value interface{}
+	}
+
+	// Logical is an and/or expression, which may not evaluate Right.
+	Logical struct {
+		Left     Expr
+		Operator token.Token
+		Right    Expr
+	}
+
+	// Set is an assignment to a property, as in object.name = value.
+	Set struct {
+		Object Expr
+		Name   token.Token
+		Value  Expr
+	}
+
+	// Super is a method lookup on the superclass, as in super.method.
+	Super struct {
+		Keyword token.Token
+		Method  token.Token
+	}
+
+	// This is the this keyword.
+	This struct {
+		Keyword token.Token
+	}
+
+	// Unary is a prefix operator applied to one operand.
+	Unary struct {
+		Operator token.Token
+		Right    Expr
+	}
+
+	// Variable is a reference to a variable by name.
+	Variable struct {
+		Name token.Token
+	}
+)
+
+func (e *Assign) Pos() token.Position   { return e.Name.Span.Start }
+func (e *BadExpr) Pos() token.Position  { return e.From }
+func (e *Binary) Pos() token.Position   { return e.Left.Pos() }
+func (e *Call) Pos() token.Position     { return e.Callee.Pos() }
+func (e *Get) Pos() token.Position      { return e.Object.Pos() }
+func (e *Grouping) Pos() token.Position { return e.Lparen.Span.Start }
+func (e *Literal) Pos() token.Position  { return e.Token.Span.Start }
+func (e *Logical) Pos() token.Position  { return e.Left.Pos() }
+func (e *Set) Pos() token.Position      { return e.Object.Pos() }
+func (e *Super) Pos() token.Position    { return e.Keyword.Span.Start }
+func (e *This) Pos() token.Position     { return e.Keyword.Span.Start }
+func (e *Unary) Pos() token.Position    { return e.Operator.Span.Start }
+func (e *Variable) Pos() token.Position { return e.Name.Span.Start }
+
+func (e *Assign) End() token.Position   { return e.Value.End() }
+func (e *BadExpr) End() token.Position  { return e.To }
+func (e *Binary) End() token.Position   { return e.Right.End() }
+func (e *Call) End() token.Position     { return e.Paren.Span.End }
+func (e *Get) End() token.Position      { return e.Name.Span.End }
+func (e *Grouping) End() token.Position { return e.Rparen.Span.End }
+func (e *Literal) End() token.Position  { return e.Token.Span.End }
+func (e *Logical) End() token.Position  { return e.Right.End() }
+func (e *Set) End() token.Position      { return e.Value.End() }
+func (e *Super) End() token.Position    { return e.Method.Span.End }
+func (e *This) End() token.Position     { return e.Keyword.Span.End }
+func (e *Unary) End() token.Position    { return e.Right.End() }
+func (e *Variable) End() token.Position { return e.Name.Span.End }
+
+func (e *Assign) Accept(v ExprVisitor) interface{}   { return v.VisitAssignExpr(e) }
+func (e *BadExpr) Accept(v ExprVisitor) interface{}  { return v.VisitBadExpr(e) }
+func (e *Binary) Accept(v ExprVisitor) interface{}   { return v.VisitBinaryExpr(e) }
+func (e *Call) Accept(v ExprVisitor) interface{}     { return v.VisitCallExpr(e) }
+func (e *Get) Accept(v ExprVisitor) interface{}      { return v.VisitGetExpr(e) }
+func (e *Grouping) Accept(v ExprVisitor) interface{} { return v.VisitGroupingExpr(e) }
+func (e *Literal) Accept(v ExprVisitor) interface{}  { return v.VisitLiteralExpr(e) }
+func (e *Logical) Accept(v ExprVisitor) interface{}  { return v.VisitLogicalExpr(e) }
+func (e *Set) Accept(v ExprVisitor) interface{}      { return v.VisitSetExpr(e) }
+func (e *Super) Accept(v ExprVisitor) interface{}    { return v.VisitSuperExpr(e) }
+func (e *This) Accept(v ExprVisitor) interface{}     { return v.VisitThisExpr(e) }
+func (e *Unary) Accept(v ExprVisitor) interface{}    { return v.VisitUnaryExpr(e) }
+func (e *Variable) Accept(v ExprVisitor) interface{} { return v.VisitVariableExpr(e) }
+
+func (*Assign) exprNode()   {}
+func (*BadExpr) exprNode()  {}
+func (*Binary) exprNode()   {}
+func (*Call) exprNode()     {}
+func (*Get) exprNode()      {}
+func (*Grouping) exprNode() {}
+func (*Literal) exprNode()  {}
+func (*Logical) exprNode()  {}
+func (*Set) exprNode()      {}
+func (*Super) exprNode()    {}
+func (*This) exprNode()     {}
+func (*Unary) exprNode()    {}
+func (*Variable) exprNode() {}
@@ -0,0 +1,120 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"xolog/token"
+)
+
+// tok returns a token for lexeme starting at offset on the first line.
+func tok(tokenType token.TokenType, lexeme string, offset int) token.Token {
+	return token.Token{
+		Type:   tokenType,
+		Lexeme: lexeme,
+		Line:   1,
+		Span: token.Span{
+			Start: token.Position{Offset: offset, Line: 1, Column: offset + 1},
+			End:   token.Position{Offset: offset + len(lexeme), Line: 1, Column: offset + len(lexeme) + 1},
+		},
+	}
+}
+
+// testExpr is the tree of "-a.b(1, (c)) or this.d = super.e".
+func testExpr() Expr {
+	return &Logical{
+		Left: &Call{
+			Callee: &Get{
+				Object: &Unary{Operator: tok(token.MINUS, "-", 0), Right: &Variable{Name: tok(token.IDENTIFIER, "a", 1)}},
+				Name:   tok(token.IDENTIFIER, "b", 3),
+			},
+			Arguments: []Expr{
+				&Literal{Token: tok(token.NUMBER, "1", 5), Value: int64(1)},
+				&Grouping{Lparen: tok(token.LEFT_PAREN, "(", 8), Expression: &Variable{Name: tok(token.IDENTIFIER, "c", 9)}, Rparen: tok(token.RIGHT_PAREN, ")", 10)},
+			},
+			Paren: tok(token.RIGHT_PAREN, ")", 11),
+		},
+		Operator: tok(token.OR, "or", 13),
+		Right: &Set{
+			Object: &This{Keyword: tok(token.THIS, "this", 16)},
+			Name:   tok(token.IDENTIFIER, "d", 21),
+			Value: &Super{
+				Keyword: tok(token.SUPER, "super", 25),
+				Method:  tok(token.IDENTIFIER, "e", 31),
+			},
+		},
+	}
+}
+
+func TestNode_Pos(t *testing.T) {
+	tests := []struct {
+		name      string
+		node      Node
+		wantStart int
+		wantEnd   int
+	}{
+		{name: "Logical spans both operands.", node: testExpr(), wantStart: 0, wantEnd: 32},
+		{name: "Call ends at the closing parenthesis.", node: testExpr().(*Logical).Left, wantStart: 0, wantEnd: 12},
+		{name: "Grouping includes its parentheses.", node: testExpr().(*Logical).Left.(*Call).Arguments[1], wantStart: 8, wantEnd: 11},
+		{name: "Set starts at its object.", node: testExpr().(*Logical).Right, wantStart: 16, wantEnd: 32},
+		{name: "Assign starts at its name.", node: &Assign{Name: tok(token.IDENTIFIER, "x", 2), Value: &Literal{Token: tok(token.NIL, "nil", 6)}}, wantStart: 2, wantEnd: 9},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.node.Pos().Offset; got != tt.wantStart {
+				t.Errorf("Pos().Offset = %v, want %v", got, tt.wantStart)
+			}
+			if got := tt.node.End().Offset; got != tt.wantEnd {
+				t.Errorf("End().Offset = %v, want %v", got, tt.wantEnd)
+			}
+		})
+	}
+}
+
+// typeNamer returns the name of each expression type it is asked to visit.
+type typeNamer struct{}
+
+func (typeNamer) VisitAssignExpr(e *Assign) interface{}     { return "Assign" }
+func (typeNamer) VisitBadExpr(e *BadExpr) interface{}       { return "BadExpr" }
+func (typeNamer) VisitBinaryExpr(e *Binary) interface{}     { return "Binary" }
+func (typeNamer) VisitCallExpr(e *Call) interface{}         { return "Call" }
+func (typeNamer) VisitGetExpr(e *Get) interface{}           { return "Get" }
+func (typeNamer) VisitGroupingExpr(e *Grouping) interface{} { return "Grouping" }
+func (typeNamer) VisitLiteralExpr(e *Literal) interface{}   { return "Literal" }
+func (typeNamer) VisitLogicalExpr(e *Logical) interface{}   { return "Logical" }
+func (typeNamer) VisitSetExpr(e *Set) interface{}           { return "Set" }
+func (typeNamer) VisitSuperExpr(e *Super) interface{}       { return "Super" }
+func (typeNamer) VisitThisExpr(e *This) interface{}         { return "This" }
+func (typeNamer) VisitUnaryExpr(e *Unary) interface{}       { return "Unary" }
+func (typeNamer) VisitVariableExpr(e *Variable) interface{} { return "Variable" }
+
+func TestExpr_Accept(t *testing.T) {
+	exprs := []Expr{&Assign{}, &BadExpr{}, &Binary{}, &Call{}, &Get{}, &Grouping{}, &Literal{}, &Logical{}, &Set{}, &Super{}, &This{}, &Unary{}, &Variable{}}
+	for _, e := range exprs {
+		want := reflect.TypeOf(e).Elem().Name()
+		if got := e.Accept(typeNamer{}); got != want {
+			t.Errorf("%T.Accept() = %v, want %v", e, got, want)
+		}
+	}
+}
+
+func ExampleInspect() {
+	Inspect(testExpr(), func(n Node) bool {
+		if n != nil {
+			fmt.Printf("%T\n", n)
+		}
+		return true
+	})
+	// Output:
+	// *ast.Logical
+	// *ast.Call
+	// *ast.Get
+	// *ast.Unary
+	// *ast.Variable
+	// *ast.Literal
+	// *ast.Grouping
+	// *ast.Variable
+	// *ast.Set
+	// *ast.This
+	// *ast.Super
+}
@@ -0,0 +1,315 @@
+package ast
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"xolog/token"
+)
+
+// WriteSexpr writes node as parenthesized S-expressions, one line per statement of a
+// Program. Omitted parts, such as a for loop without a condition, are written as ().
+func WriteSexpr(w io.Writer, node Node) error {
+	var b strings.Builder
+	p := sexprPrinter{&b}
+	switch n := node.(type) {
+	case *Program:
+		for _, s := range n.Statements {
+			p.stmt(s)
+			b.WriteString("\n")
+		}
+	case Stmt:
+		p.stmt(n)
+		b.WriteString("\n")
+	case Expr:
+		p.expr(n)
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// sexprPrinter writes S-expressions as it visits nodes.
+type sexprPrinter struct {
+	b *strings.Builder
+}
+
+// list will write a parenthesized list of head followed by parts, which are strings,
+// tokens, or nodes; nil nodes are written as ().
+func (p sexprPrinter) list(head string, parts ...interface{}) interface{} {
+	p.b.WriteString("(" + head)
+	for _, part := range parts {
+		p.b.WriteString(" ")
+		switch part := part.(type) {
+		case string:
+			p.b.WriteString(part)
+		case token.Token:
+			p.b.WriteString(part.Lexeme)
+		case Expr:
+			p.expr(part)
+		case Stmt:
+			p.stmt(part)
+		default:
+			p.b.WriteString("()")
+		}
+	}
+	p.b.WriteString(")")
+	return nil
+}
+
+// expr will write e, or () when e is nil.
+func (p sexprPrinter) expr(e Expr) {
+	if e == nil {
+		p.b.WriteString("()")
+		return
+	}
+	e.Accept(p)
+}
+
+// stmt will write s, or () when s is nil.
+func (p sexprPrinter) stmt(s Stmt) {
+	if s == nil {
+		p.b.WriteString("()")
+		return
+	}
+	s.Accept(p)
+}
+
+func (p sexprPrinter) VisitAssignExpr(e *Assign) interface{} {
+	return p.list("=", e.Name, e.Value)
+}
+
+func (p sexprPrinter) VisitBadExpr(e *BadExpr) interface{} {
+	return p.list("bad")
+}
+
+func (p sexprPrinter) VisitBinaryExpr(e *Binary) interface{} {
+	return p.list(e.Operator.Lexeme, e.Left, e.Right)
+}
+
+func (p sexprPrinter) VisitCallExpr(e *Call) interface{} {
+	parts := []interface{}{e.Callee}
+	for _, arg := range e.Arguments {
+		parts = append(parts, arg)
+	}
+	return p.list("call", parts...)
+}
+
+func (p sexprPrinter) VisitGetExpr(e *Get) interface{} {
+	return p.list(".", e.Object, e.Name)
+}
+
+func (p sexprPrinter) VisitGroupingExpr(e *Grouping) interface{} {
+	return p.list("group", e.Expression)
+}
+
+func (p sexprPrinter) VisitLiteralExpr(e *Literal) interface{} {
+	p.b.WriteString(literal(e))
+	return nil
+}
+
+func (p sexprPrinter) VisitLogicalExpr(e *Logical) interface{} {
+	return p.list(e.Operator.Lexeme, e.Left, e.Right)
+}
+
+func (p sexprPrinter) VisitSetExpr(e *Set) interface{} {
+	return p.list("set", e.Object, e.Name, e.Value)
+}
+
+func (p sexprPrinter) VisitSuperExpr(e *Super) interface{} {
+	return p.list("super", e.Method)
+}
+
+func (p sexprPrinter) VisitThisExpr(e *This) interface{} {
+	p.b.WriteString("this")
+	return nil
+}
+
+func (p sexprPrinter) VisitUnaryExpr(e *Unary) interface{} {
+	return p.list(e.Operator.Lexeme, e.Right)
+}
+
+func (p sexprPrinter) VisitVariableExpr(e *Variable) interface{} {
+	p.b.WriteString(e.Name.Lexeme)
+	return nil
+}
+
+func (p sexprPrinter) VisitBadStmt(s *BadStmt) interface{} {
+	return p.list("bad")
+}
+
+func (p sexprPrinter) VisitBlockStmt(s *BlockStmt) interface{} {
+	return p.list("block", stmts(s.Statements)...)
+}
+
+func (p sexprPrinter) VisitClassStmt(s *ClassStmt) interface{} {
+	parts := []interface{}{s.Name}
+	if s.Superclass != nil {
+		parts = append(parts, "<", s.Superclass)
+	}
+	for _, method := range s.Methods {
+		parts = append(parts, method)
+	}
+	return p.list("class", parts...)
+}
+
+func (p sexprPrinter) VisitExpressionStmt(s *ExpressionStmt) interface{} {
+	return p.list(";", s.Expression)
+}
+
+func (p sexprPrinter) VisitForStmt(s *ForStmt) interface{} {
+	return p.list("for", s.Initializer, s.Condition, s.Increment, s.Body)
+}
+
+func (p sexprPrinter) VisitFunStmt(s *FunStmt) interface{} {
+	return p.list("fun", s.Name, "("+params(s)+")", s.Body)
+}
+
+func (p sexprPrinter) VisitIfStmt(s *IfStmt) interface{} {
+	if s.Else == nil {
+		return p.list("if", s.Condition, s.Then)
+	}
+	return p.list("if", s.Condition, s.Then, s.Else)
+}
+
+func (p sexprPrinter) VisitPrintStmt(s *PrintStmt) interface{} {
+	return p.list("print", s.Expression)
+}
+
+func (p sexprPrinter) VisitReturnStmt(s *ReturnStmt) interface{} {
+	if s.Value == nil {
+		return p.list("return")
+	}
+	return p.list("return", s.Value)
+}
+
+func (p sexprPrinter) VisitVarStmt(s *VarStmt) interface{} {
+	if s.Initializer == nil {
+		return p.list("var", s.Name)
+	}
+	return p.list("var", s.Name, s.Initializer)
+}
+
+func (p sexprPrinter) VisitWhileStmt(s *WhileStmt) interface{} {
+	return p.list("while", s.Condition, s.Body)
+}
+
+// stmts will convert list for use as the parts of sexprPrinter.list.
+func stmts(list []Stmt) []interface{} {
+	parts := make([]interface{}, len(list))
+	for i, s := range list {
+		parts[i] = s
+	}
+	return parts
+}
+
+// params will return the parameter names of s separated by spaces.
+func params(s *FunStmt) string {
+	names := make([]string, len(s.Params))
+	for i, param := range s.Params {
+		names[i] = param.Lexeme
+	}
+	return strings.Join(names, " ")
+}
+
+// literal will return the value of e as it is written in the text encoding of a Token.
+func literal(e *Literal) string {
+	text, err := token.FormatLiteral(e.Value)
+	if err != nil {
+		return e.Token.Lexeme
+	}
+	return text
+}
+
+// WriteTree writes node as an indented tree, one node per line with its kind, span and a
+// short description, children indented below their parent.
+func WriteTree(w io.Writer, node Node) error {
+	var b strings.Builder
+	depth := 0
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			depth--
+			return false
+		}
+		start, end := n.Pos(), n.End()
+		fmt.Fprintf(&b, "%s%s %d:%d-%d:%d", strings.Repeat("  ", depth), kind(n), start.Line, start.Column, end.Line, end.Column)
+		if d := describe(n); d != "" {
+			b.WriteString(" " + d)
+		}
+		b.WriteString("\n")
+		depth++
+		return true
+	})
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// WriteDOT writes node as a Graphviz digraph, with an edge from each node to its children.
+func WriteDOT(w io.Writer, node Node) error {
+	var b strings.Builder
+	b.WriteString("digraph ast {\n\tnode [shape=box, fontname=\"monospace\"];\n")
+	var parents []int
+	count := 0
+	Inspect(node, func(n Node) bool {
+		if n == nil {
+			parents = parents[:len(parents)-1]
+			return false
+		}
+		label := kind(n)
+		if d := describe(n); d != "" {
+			label += "\n" + d
+		}
+		fmt.Fprintf(&b, "\tn%d [label=\"%s\"];\n", count, escapeDOT(label))
+		if len(parents) > 0 {
+			fmt.Fprintf(&b, "\tn%d -> n%d;\n", parents[len(parents)-1], count)
+		}
+		parents = append(parents, count)
+		count++
+		return true
+	})
+	b.WriteString("}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// escapeDOT will escape s for a double quoted DOT string.
+func escapeDOT(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s)
+}
+
+// kind will return the name of the type of n, without the package.
+func kind(n Node) string {
+	return strings.TrimPrefix(fmt.Sprintf("%T", n), "*ast.")
+}
+
+// describe will return what distinguishes n from other nodes of its kind, if anything:
+// its operator, name or value.
+func describe(n Node) string {
+	switch n := n.(type) {
+	case *Assign:
+		return n.Name.Lexeme
+	case *Binary:
+		return n.Operator.Lexeme
+	case *Get:
+		return n.Name.Lexeme
+	case *Literal:
+		return literal(n)
+	case *Logical:
+		return n.Operator.Lexeme
+	case *Set:
+		return n.Name.Lexeme
+	case *Super:
+		return n.Method.Lexeme
+	case *Unary:
+		return n.Operator.Lexeme
+	case *Variable:
+		return n.Name.Lexeme
+	case *ClassStmt:
+		return n.Name.Lexeme
+	case *FunStmt:
+		return n.Name.Lexeme + "(" + strings.Replace(params(n), " ", ", ", -1) + ")"
+	case *VarStmt:
+		return n.Name.Lexeme
+	}
+	return ""
+}
@@ -0,0 +1,100 @@
+package ast
+
+import (
+	"bytes"
+	"testing"
+	"xolog/token"
+)
+
+// testProgram is the tree of "var s = "a\"b";\nfor (;;) print -a.b(1, (c)) or this.d = super.e;".
+func testProgram() *Program {
+	return &Program{
+		Statements: []Stmt{
+			&VarStmt{
+				Keyword:     tok(token.VAR, "var", 0),
+				Name:        tok(token.IDENTIFIER, "s", 4),
+				Initializer: &Literal{Token: tok(token.STRING, `"a\"b"`, 8), Value: `a"b`},
+				Semicolon:   tok(token.SEMICOLON, ";", 14),
+			},
+			&ForStmt{
+				Keyword: tok(token.FOR, "for", 16),
+				Body:    &PrintStmt{Keyword: tok(token.PRINT, "print", 25), Expression: testExpr(), Semicolon: tok(token.SEMICOLON, ";", 60)},
+			},
+			&BadStmt{},
+		},
+		EOF: tok(token.EOF, "", 61),
+	}
+}
+
+func TestWriteSexpr(t *testing.T) {
+	tests := []struct {
+		name string
+		node Node
+		want string
+	}{
+		{
+			name: "Write one line per statement of a program.",
+			node: testProgram(),
+			want: "(var s \"a\\\"b\")\n" +
+				"(for () () () (print (or (call (. (- a) b) 1 (group c)) (set this d (super e)))))\n" +
+				"(bad)\n",
+		},
+		{
+			name: "Write a class and its methods.",
+			node: &ClassStmt{
+				Name:       tok(token.IDENTIFIER, "A", 6),
+				Superclass: &Variable{Name: tok(token.IDENTIFIER, "B", 10)},
+				Methods: []*FunStmt{{
+					Name:   tok(token.IDENTIFIER, "f", 14),
+					Params: []token.Token{tok(token.IDENTIFIER, "x", 16), tok(token.IDENTIFIER, "y", 19)},
+					Body:   &BlockStmt{Statements: []Stmt{&ReturnStmt{}}},
+				}},
+			},
+			want: "(class A < B (fun f (x y) (block (return))))\n",
+		},
+		{
+			name: "Write an expression.",
+			node: &Binary{Left: &Literal{Value: 1.5}, Operator: tok(token.STAR, "*", 4), Right: &Literal{Value: nil}},
+			want: "(* 1.5 nil)\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var b bytes.Buffer
+			if err := WriteSexpr(&b, tt.node); err != nil {
+				t.Fatalf("WriteSexpr() error = %v", err)
+			}
+			if got := b.String(); got != tt.want {
+				t.Errorf("WriteSexpr() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteTree(&b, testProgram().Statements[0]); err != nil {
+		t.Fatalf("WriteTree() error = %v", err)
+	}
+	want := "VarStmt 1:1-1:16 s\n" +
+		"  Literal 1:9-1:15 \"a\\\"b\"\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteTree() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteDOT(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteDOT(&b, testProgram().Statements[0]); err != nil {
+		t.Fatalf("WriteDOT() error = %v", err)
+	}
+	want := "digraph ast {\n" +
+		"\tnode [shape=box, fontname=\"monospace\"];\n" +
+		"\tn0 [label=\"VarStmt\\ns\"];\n" +
+		"\tn1 [label=\"Literal\\n\\\"a\\\\\\\"b\\\"\"];\n" +
+		"\tn0 -> n1;\n" +
+		"}\n"
+	if got := b.String(); got != want {
+		t.Errorf("WriteDOT() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,192 @@
+package ast
+
+import "xolog/token"
+
+// Stmt is implemented by every statement and declaration node.
+type Stmt interface {
+	Node
+	// Accept calls the method of v for the concrete type of the statement, and returns its result.
+	Accept(v StmtVisitor) interface{}
+	stmtNode()
+}
+
+// StmtVisitor has one method per statement node, for use with Stmt.Accept.
+type StmtVisitor interface {
+	VisitBadStmt(s *BadStmt) interface{}
+	VisitBlockStmt(s *BlockStmt) interface{}
+	VisitClassStmt(s *ClassStmt) interface{}
+	VisitExpressionStmt(s *ExpressionStmt) interface{}
+	VisitForStmt(s *ForStmt) interface{}
+	VisitFunStmt(s *FunStmt) interface{}
+	VisitIfStmt(s *IfStmt) interface{}
+	VisitPrintStmt(s *PrintStmt) interface{}
+	VisitReturnStmt(s *ReturnStmt) interface{}
+	VisitVarStmt(s *VarStmt) interface{}
+	VisitWhileStmt(s *WhileStmt) interface{}
+}
+
+// Program is the root of the tree for a whole script.
+type Program struct {
+	Statements []Stmt
+	EOF        token.Token
+}
+
+// Pos returns the start of the first statement, or of EOF in an empty program.
+func (p *Program) Pos() token.Position {
+	if len(p.Statements) > 0 {
+		return p.Statements[0].Pos()
+	}
+	return p.EOF.Span.Start
+}
+
+// End returns the end of the source.
+func (p *Program) End() token.Position { return p.EOF.Span.End }
+
+type (
+	// BadStmt is a placeholder for the tokens skipped after a syntax error.
+	BadStmt struct {
+		From token.Position
+		To   token.Position
+	}
+
+	// BlockStmt is a braced list of statements with its own scope.
+	BlockStmt struct {
+		Lbrace     token.Token
+		Statements []Stmt
+		Rbrace     token.Token
+	}
+
+	// ClassStmt is a class declaration. Superclass is nil when there is no < clause.
+	ClassStmt struct {
+		Doc        []token.Token // DOC_COMMENT tokens directly above the declaration
+		Keyword    token.Token
+		Name       token.Token
+		Superclass *Variable
+		Methods    []*FunStmt
+		Rbrace     token.Token
+	}
+
+	// ExpressionStmt is an expression evaluated for its effect.
+	ExpressionStmt struct {
+		Expression Expr
+		Semicolon  token.Token
+	}
+
+	// ForStmt is a for loop. Initializer, Condition and Increment are nil when omitted.
+	ForStmt struct {
+		Keyword     token.Token
+		Initializer Stmt
+		Condition   Expr
+		Increment   Expr
+		Body        Stmt
+	}
+
+	// FunStmt is a function declaration, or a method when Keyword is the zero Token.
+	FunStmt struct {
+		Doc     []token.Token // DOC_COMMENT tokens directly above the declaration
+		Keyword token.Token
+		Name    token.Token
+		Params  []token.Token
+		Body    *BlockStmt
+	}
+
+	// IfStmt is a conditional statement. Else is nil when there is no else branch.
+	IfStmt struct {
+		Keyword   token.Token
+		Condition Expr
+		Then      Stmt
+		Else      Stmt
+	}
+
+	// PrintStmt prints the value of an expression.
+	PrintStmt struct {
+		Keyword    token.Token
+		Expression Expr
+		Semicolon  token.Token
+	}
+
+	// ReturnStmt returns from a function. Value is nil for a bare return.
+	ReturnStmt struct {
+		Keyword   token.Token
+		Value     Expr
+		Semicolon token.Token
+	}
+
+	// VarStmt is a variable declaration. Initializer is nil when there is no = clause.
+	VarStmt struct {
+		Doc         []token.Token // DOC_COMMENT tokens directly above the declaration
+		Keyword     token.Token
+		Name        token.Token
+		Initializer Expr
+		Semicolon   token.Token
+	}
+
+	// WhileStmt is a while loop.
+	WhileStmt struct {
+		Keyword   token.Token
+		Condition Expr
+		Body      Stmt
+	}
+)
+
+func (s *BadStmt) Pos() token.Position        { return s.From }
+func (s *BlockStmt) Pos() token.Position      { return s.Lbrace.Span.Start }
+func (s *ClassStmt) Pos() token.Position      { return s.Keyword.Span.Start }
+func (s *ExpressionStmt) Pos() token.Position { return s.Expression.Pos() }
+func (s *ForStmt) Pos() token.Position        { return s.Keyword.Span.Start }
+func (s *IfStmt) Pos() token.Position         { return s.Keyword.Span.Start }
+func (s *PrintStmt) Pos() token.Position      { return s.Keyword.Span.Start }
+func (s *ReturnStmt) Pos() token.Position     { return s.Keyword.Span.Start }
+func (s *VarStmt) Pos() token.Position        { return s.Keyword.Span.Start }
+func (s *WhileStmt) Pos() token.Position      { return s.Keyword.Span.Start }
+
+// Pos returns the start of the fun keyword, or of the name of a method.
+func (s *FunStmt) Pos() token.Position {
+	if s.Keyword.Type == token.FUN {
+		return s.Keyword.Span.Start
+	}
+	return s.Name.Span.Start
+}
+
+func (s *BadStmt) End() token.Position        { return s.To }
+func (s *BlockStmt) End() token.Position      { return s.Rbrace.Span.End }
+func (s *ClassStmt) End() token.Position      { return s.Rbrace.Span.End }
+func (s *ExpressionStmt) End() token.Position { return s.Semicolon.Span.End }
+func (s *ForStmt) End() token.Position        { return s.Body.End() }
+func (s *FunStmt) End() token.Position        { return s.Body.End() }
+func (s *PrintStmt) End() token.Position      { return s.Semicolon.Span.End }
+func (s *ReturnStmt) End() token.Position     { return s.Semicolon.Span.End }
+func (s *VarStmt) End() token.Position        { return s.Semicolon.Span.End }
+func (s *WhileStmt) End() token.Position      { return s.Body.End() }
+
+// End returns the end of the else branch, or of the then branch when there is none.
+func (s *IfStmt) End() token.Position {
+	if s.Else != nil {
+		return s.Else.End()
+	}
+	return s.Then.End()
+}
+
+func (s *BadStmt) Accept(v StmtVisitor) interface{}        { return v.VisitBadStmt(s) }
+func (s *BlockStmt) Accept(v StmtVisitor) interface{}      { return v.VisitBlockStmt(s) }
+func (s *ClassStmt) Accept(v StmtVisitor) interface{}      { return v.VisitClassStmt(s) }
+func (s *ExpressionStmt) Accept(v StmtVisitor) interface{} { return v.VisitExpressionStmt(s) }
+func (s *ForStmt) Accept(v StmtVisitor) interface{}        { return v.VisitForStmt(s) }
+func (s *FunStmt) Accept(v StmtVisitor) interface{}        { return v.VisitFunStmt(s) }
+func (s *IfStmt) Accept(v StmtVisitor) interface{}         { return v.VisitIfStmt(s) }
+func (s *PrintStmt) Accept(v StmtVisitor) interface{}      { return v.VisitPrintStmt(s) }
+func (s *ReturnStmt) Accept(v StmtVisitor) interface{}     { return v.VisitReturnStmt(s) }
+func (s *VarStmt) Accept(v StmtVisitor) interface{}        { return v.VisitVarStmt(s) }
+func (s *WhileStmt) Accept(v StmtVisitor) interface{}      { return v.VisitWhileStmt(s) }
+
+func (*BadStmt) stmtNode()        {}
+func (*BlockStmt) stmtNode()      {}
+func (*ClassStmt) stmtNode()      {}
+func (*ExpressionStmt) stmtNode() {}
+func (*ForStmt) stmtNode()        {}
+func (*FunStmt) stmtNode()        {}
+func (*IfStmt) stmtNode()         {}
+func (*PrintStmt) stmtNode()      {}
+func (*ReturnStmt) stmtNode()     {}
+func (*VarStmt) stmtNode()        {}
+func (*WhileStmt) stmtNode()      {}
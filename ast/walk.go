@@ -0,0 +1,116 @@
+package ast
+
+import "fmt"
+
+// Visitor is called by Walk for each node. If Visit returns a non-nil visitor w, Walk
+// visits each child of node with w, then calls w.Visit(nil).
+type Visitor interface {
+	Visit(node Node) (w Visitor)
+}
+
+// Walk traverses the tree rooted at node in depth-first order, children in source order.
+func Walk(v Visitor, node Node) {
+	if v = v.Visit(node); v == nil {
+		return
+	}
+
+	switch n := node.(type) {
+	case *Assign:
+		Walk(v, n.Value)
+	case *Binary:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *Call:
+		Walk(v, n.Callee)
+		for _, arg := range n.Arguments {
+			Walk(v, arg)
+		}
+	case *Get:
+		Walk(v, n.Object)
+	case *Grouping:
+		Walk(v, n.Expression)
+	case *Logical:
+		Walk(v, n.Left)
+		Walk(v, n.Right)
+	case *Set:
+		Walk(v, n.Object)
+		Walk(v, n.Value)
+	case *Unary:
+		Walk(v, n.Right)
+	case *BadExpr, *Literal, *Super, *This, *Variable:
+		// No children.
+
+	case *Program:
+		walkStmts(v, n.Statements)
+	case *BlockStmt:
+		walkStmts(v, n.Statements)
+	case *BadStmt:
+		// No children.
+	case *ClassStmt:
+		if n.Superclass != nil {
+			Walk(v, n.Superclass)
+		}
+		for _, method := range n.Methods {
+			Walk(v, method)
+		}
+	case *ExpressionStmt:
+		Walk(v, n.Expression)
+	case *ForStmt:
+		if n.Initializer != nil {
+			Walk(v, n.Initializer)
+		}
+		if n.Condition != nil {
+			Walk(v, n.Condition)
+		}
+		if n.Increment != nil {
+			Walk(v, n.Increment)
+		}
+		Walk(v, n.Body)
+	case *FunStmt:
+		Walk(v, n.Body)
+	case *IfStmt:
+		Walk(v, n.Condition)
+		Walk(v, n.Then)
+		if n.Else != nil {
+			Walk(v, n.Else)
+		}
+	case *PrintStmt:
+		Walk(v, n.Expression)
+	case *ReturnStmt:
+		if n.Value != nil {
+			Walk(v, n.Value)
+		}
+	case *VarStmt:
+		if n.Initializer != nil {
+			Walk(v, n.Initializer)
+		}
+	case *WhileStmt:
+		Walk(v, n.Condition)
+		Walk(v, n.Body)
+	default:
+		panic(fmt.Sprintf("ast.Walk: unexpected node type %T", n))
+	}
+
+	v.Visit(nil)
+}
+
+func walkStmts(v Visitor, list []Stmt) {
+	for _, s := range list {
+		Walk(v, s)
+	}
+}
+
+type inspector func(Node) bool
+
+func (f inspector) Visit(node Node) Visitor {
+	if f(node) {
+		return f
+	}
+	return nil
+}
+
+// Inspect traverses the tree rooted at node in depth-first order, calling f for each node.
+// Children are skipped when f returns false. After the children of a node, f(nil) is called.
+func Inspect(node Node, f func(Node) bool) {
+	Walk(inspector(f), node)
+}
@@ -0,0 +1,85 @@
+package ast
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"xolog/token"
+)
+
+// recorder records the nodes it visits, with nil marking the end of a node's children.
+type recorder struct {
+	visited *[]Node
+}
+
+func (r recorder) Visit(node Node) Visitor {
+	*r.visited = append(*r.visited, node)
+	return r
+}
+
+func TestWalk(t *testing.T) {
+	var visited []Node
+	e := testExpr().(*Logical).Left.(*Call).Arguments[1].(*Grouping)
+	Walk(recorder{&visited}, e)
+	want := []Node{e, e.Expression, nil, nil}
+	if !reflect.DeepEqual(visited, want) {
+		t.Errorf("Walk() visited %v, want %v", visited, want)
+	}
+}
+
+func TestInspect_prune(t *testing.T) {
+	var names []string
+	Inspect(testExpr(), func(n Node) bool {
+		if v, ok := n.(*Variable); ok {
+			names = append(names, v.Name.Lexeme)
+		}
+		_, isCall := n.(*Call)
+		return !isCall
+	})
+	if len(names) != 0 {
+		t.Errorf("Inspect() reached variables %v below a pruned call", names)
+	}
+
+	names = nil
+	Inspect(testExpr(), func(n Node) bool {
+		if v, ok := n.(*Variable); ok {
+			names = append(names, v.Name.Lexeme)
+		}
+		return true
+	})
+	if want := []string{"a", "c"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Inspect() found variables %v, want %v", names, want)
+	}
+}
+
+func TestInspect_statements(t *testing.T) {
+	i := &Variable{Name: tok(token.IDENTIFIER, "i", 9)}
+	loop := &ForStmt{
+		Keyword:   tok(token.FOR, "for", 0),
+		Condition: i,
+		Body: &BlockStmt{
+			Lbrace:     tok(token.LEFT_BRACE, "{", 12),
+			Statements: []Stmt{&ReturnStmt{Keyword: tok(token.RETURN, "return", 14), Semicolon: tok(token.SEMICOLON, ";", 20)}},
+			Rbrace:     tok(token.RIGHT_BRACE, "}", 22),
+		},
+	}
+	program := &Program{Statements: []Stmt{loop}, EOF: tok(token.EOF, "", 23)}
+
+	var got []string
+	Inspect(program, func(n Node) bool {
+		if n != nil {
+			got = append(got, fmt.Sprintf("%T", n))
+		}
+		return true
+	})
+	want := []string{"*ast.Program", "*ast.ForStmt", "*ast.Variable", "*ast.BlockStmt", "*ast.ReturnStmt"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Inspect() visited %v, want %v", got, want)
+	}
+	if pos, end := program.Pos().Offset, program.End().Offset; pos != 0 || end != 23 {
+		t.Errorf("Program spans %d-%d, want 0-23", pos, end)
+	}
+	if pos, end := loop.Pos().Offset, loop.End().Offset; pos != 0 || end != 23 {
+		t.Errorf("ForStmt spans %d-%d, want 0-23", pos, end)
+	}
+}
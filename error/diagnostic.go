@@ -0,0 +1,188 @@
+package error
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"xolog/token"
+)
+
+// Severity classifies how serious a Diagnostic is.
+type Severity int
+
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+	SeverityNote
+)
+
+var severities = [...]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+func (s Severity) String() string {
+	if s >= 0 && int(s) < len(severities) {
+		return severities[s]
+	}
+	return fmt.Sprintf("severity(%d)", int(s))
+}
+
+// MarshalText encodes the severity by name.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// UnmarshalText decodes a severity name.
+func (s *Severity) UnmarshalText(text []byte) error {
+	for i, name := range severities {
+		if name == string(text) {
+			*s = Severity(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown severity %q", text)
+}
+
+// Label attaches a message to a secondary span of source text.
+type Label struct {
+	Span    token.Span `json:"span"`
+	Message string     `json:"message"`
+}
+
+// Diagnostic is a single problem found in source text.
+type Diagnostic struct {
+	Severity Severity   `json:"severity"`
+	Span     token.Span `json:"span"`
+	Code     string     `json:"code,omitempty"`
+	Message  string     `json:"message"`
+	Notes    []string   `json:"notes,omitempty"`
+	Labels   []Label    `json:"labels,omitempty"`
+}
+
+// Error formats the diagnostic as "file:line:col: severity[code]: message".
+func (d Diagnostic) Error() string {
+	var b strings.Builder
+	if d.Span.Start.IsValid() || d.Span.Start.Filename != "" {
+		b.WriteString(d.Span.Start.String())
+		b.WriteString(": ")
+	}
+	b.WriteString(d.Severity.String())
+	if d.Code != "" {
+		fmt.Fprintf(&b, "[%s]", d.Code)
+	}
+	b.WriteString(": ")
+	b.WriteString(d.Message)
+	return b.String()
+}
+
+// Reporter receives diagnostics as they are found.
+type Reporter interface {
+	Report(d Diagnostic)
+}
+
+// ReporterFunc adapts an ordinary function to a Reporter.
+type ReporterFunc func(d Diagnostic)
+
+// Report calls f(d).
+func (f ReporterFunc) Report(d Diagnostic) {
+	f(d)
+}
+
+// CodeTooManyErrors is the code of the note a Reporter made by Limit adds once it drops errors.
+const CodeTooManyErrors = "E0001"
+
+// limiter is a Reporter that passes on at most max errors.
+type limiter struct {
+	r      Reporter
+	max    int
+	errors int
+}
+
+// Limit returns a Reporter that passes diagnostics on to r until n errors have been
+// reported. Later errors are dropped, the first of them replaced by a note saying so;
+// other severities always pass. A non-positive n returns r unchanged.
+func Limit(r Reporter, n int) Reporter {
+	if n <= 0 {
+		return r
+	}
+	return &limiter{r: r, max: n}
+}
+
+// Report passes d on to the underlying Reporter unless the error limit has been reached.
+func (l *limiter) Report(d Diagnostic) {
+	if d.Severity == SeverityError {
+		l.errors++
+		if l.errors > l.max {
+			if l.errors == l.max+1 {
+				l.r.Report(Diagnostic{
+					Severity: SeverityNote,
+					Span:     d.Span,
+					Code:     CodeTooManyErrors,
+					Message:  fmt.Sprintf("Too many errors; only the first %d are reported.", l.max),
+				})
+			}
+			return
+		}
+	}
+	l.r.Report(d)
+}
+
+// List collects diagnostics; a *List is a Reporter.
+type List []Diagnostic
+
+// Report appends d to the list.
+func (l *List) Report(d Diagnostic) {
+	*l = append(*l, d)
+}
+
+func (l List) Len() int      { return len(l) }
+func (l List) Swap(i, j int) { l[i], l[j] = l[j], l[i] }
+
+// Less orders diagnostics by file name, offset, then severity.
+func (l List) Less(i, j int) bool {
+	a, b := l[i].Span.Start, l[j].Span.Start
+	if a.Filename != b.Filename {
+		return a.Filename < b.Filename
+	}
+	if a.Offset != b.Offset {
+		return a.Offset < b.Offset
+	}
+	return l[i].Severity < l[j].Severity
+}
+
+// Sort sorts the list in place, keeping diagnostics at the same position in report order.
+func (l List) Sort() {
+	sort.Stable(l)
+}
+
+// Filter returns the diagnostics for which keep returns true.
+func (l List) Filter(keep func(d Diagnostic) bool) List {
+	var out List
+	for _, d := range l {
+		if keep(d) {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// HasErrors reports whether any diagnostic in the list has SeverityError.
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// Error implements the error interface, one diagnostic per line.
+func (l List) Error() string {
+	lines := make([]string, len(l))
+	for i, d := range l {
+		lines[i] = d.Error()
+	}
+	return strings.Join(lines, "\n")
+}
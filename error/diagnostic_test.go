@@ -0,0 +1,107 @@
+package error
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+	"xolog/token"
+)
+
+func TestDiagnostic_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		d    Diagnostic
+		want string
+	}{
+		{
+			name: "Diagnostic with file, position and code.",
+			d: Diagnostic{
+				Severity: SeverityError,
+				Span:     token.Span{Start: token.Position{Filename: "a.xo", Line: 2, Column: 5}},
+				Code:     "S0001",
+				Message:  "Unexpected character: @",
+			},
+			want: "a.xo:2:5: error[S0001]: Unexpected character: @",
+		},
+		{
+			name: "Warning without position or code.",
+			d:    Diagnostic{Severity: SeverityWarning, Message: "Careful."},
+			want: "warning: Careful.",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.d.Error(); got != tt.want {
+				t.Errorf("Diagnostic.Error() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestList_Sort(t *testing.T) {
+	at := func(filename string, offset int) token.Span {
+		return token.Span{Start: token.Position{Filename: filename, Offset: offset, Line: 1, Column: offset + 1}}
+	}
+	l := List{
+		{Severity: SeverityNote, Span: at("b.xo", 0), Message: "1"},
+		{Severity: SeverityError, Span: at("a.xo", 4), Message: "2"},
+		{Severity: SeverityWarning, Span: at("a.xo", 1), Message: "3"},
+		{Severity: SeverityError, Span: at("b.xo", 0), Message: "4"},
+	}
+	l.Sort()
+	var got []string
+	for _, d := range l {
+		got = append(got, d.Message)
+	}
+	if want := []string{"3", "2", "4", "1"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List.Sort() order = %v, want %v", got, want)
+	}
+}
+
+func TestList_HasErrors(t *testing.T) {
+	l := List{}
+	l.Report(Diagnostic{Severity: SeverityWarning})
+	if l.HasErrors() {
+		t.Errorf("List.HasErrors() = true, want false")
+	}
+	l.Report(Diagnostic{Severity: SeverityError})
+	if !l.HasErrors() {
+		t.Errorf("List.HasErrors() = false, want true")
+	}
+	if got := len(l.Filter(func(d Diagnostic) bool { return d.Severity == SeverityError })); got != 1 {
+		t.Errorf("List.Filter() returned %d diagnostics, want 1", got)
+	}
+}
+
+func TestPrinter_Report(t *testing.T) {
+	buf := bytes.Buffer{}
+	NewPrinter(&buf).Report(Diagnostic{
+		Severity: SeverityError,
+		Span:     token.Span{Start: token.Position{Line: 1, Column: 1}},
+		Message:  "Unterminated string.",
+		Notes:    []string{"strings close with the quote they open with"},
+	})
+	want := "1:1: error: Unterminated string.\n\tnote: strings close with the quote they open with\n"
+	if got := buf.String(); got != want {
+		t.Errorf("Printer.Report() wrote %q, want %q", got, want)
+	}
+}
+
+func TestLimit(t *testing.T) {
+	list := List{}
+	r := Limit(&list, 2)
+	for _, severity := range []Severity{SeverityError, SeverityWarning, SeverityError, SeverityError, SeverityNote, SeverityError} {
+		r.Report(Diagnostic{Severity: severity, Message: severity.String()})
+	}
+	var got []string
+	for _, d := range list {
+		got = append(got, d.Severity.String()+" "+d.Code)
+	}
+	want := []string{"error ", "warning ", "error ", "note " + CodeTooManyErrors, "note "}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Limit() passed on %v, want %v", got, want)
+	}
+	if r := Limit(&list, 0); r != Reporter(&list) {
+		t.Errorf("Limit(r, 0) = %v, want r", r)
+	}
+}
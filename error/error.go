@@ -2,12 +2,33 @@ package error
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"xolog/token"
 )
 
+// Default receives diagnostics from components that were not given a Reporter.
+var Default Reporter = NewPrinter(os.Stderr)
+
+// Error reports message at line through Default.
 func Error(line int, message string) {
-	report(line, "", message)
+	Default.Report(Diagnostic{Severity: SeverityError, Span: token.Span{Start: token.Position{Line: line}}, Message: message})
+}
+
+// Printer is a Reporter that writes each diagnostic as plain text.
+type Printer struct {
+	w io.Writer
+}
+
+// NewPrinter returns a pointer to a Printer writing to w.
+func NewPrinter(w io.Writer) *Printer {
+	return &Printer{w: w}
 }
 
-func report(line int, where string, message string) {
-	fmt.Printf("\n[line %d] Error %s : %s\n", line, where, message)
+// Report writes d and its notes to the underlying writer.
+func (p *Printer) Report(d Diagnostic) {
+	fmt.Fprintln(p.w, d.Error())
+	for _, note := range d.Notes {
+		fmt.Fprintf(p.w, "\tnote: %s\n", note)
+	}
 }
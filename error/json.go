@@ -0,0 +1,21 @@
+package error
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// jsonDocument is the top-level shape written by WriteJSON.
+type jsonDocument struct {
+	Diagnostics List `json:"diagnostics"`
+}
+
+// WriteJSON writes diagnostics to w as a single JSON document.
+func WriteJSON(w io.Writer, diagnostics List) error {
+	if diagnostics == nil {
+		diagnostics = List{}
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(jsonDocument{Diagnostics: diagnostics})
+}
@@ -0,0 +1,49 @@
+package error
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"xolog/token"
+)
+
+func TestWriteJSON(t *testing.T) {
+	diagnostics := List{
+		{
+			Severity: SeverityWarning,
+			Span: token.Span{
+				Start: token.Position{Filename: "a.xo", Offset: 4, Line: 1, Column: 5},
+				End:   token.Position{Filename: "a.xo", Offset: 5, Line: 1, Column: 6},
+			},
+			Code:    "S0001",
+			Message: "Unexpected character: @",
+			Notes:   []string{"a note"},
+		},
+	}
+	buf := bytes.Buffer{}
+	if err := WriteJSON(&buf, diagnostics); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+
+	var got jsonDocument
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if !reflect.DeepEqual(got.Diagnostics, diagnostics) {
+		t.Errorf("WriteJSON() round trip = %v, want %v", got.Diagnostics, diagnostics)
+	}
+	if !bytes.Contains(buf.Bytes(), []byte(`"severity": "warning"`)) {
+		t.Errorf("WriteJSON() did not encode severity by name:\n%s", buf.String())
+	}
+}
+
+func TestWriteJSON_empty(t *testing.T) {
+	buf := bytes.Buffer{}
+	if err := WriteJSON(&buf, nil); err != nil {
+		t.Fatalf("WriteJSON() error = %v", err)
+	}
+	if got, want := buf.String(), "{\n  \"diagnostics\": []\n}\n"; got != want {
+		t.Errorf("WriteJSON() = %q, want %q", got, want)
+	}
+}
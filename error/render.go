@@ -0,0 +1,298 @@
+package error
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+	"xolog/token"
+)
+
+// ANSI escape sequences used when colour is enabled.
+const (
+	ansiReset  = "\x1b[0m"
+	ansiBold   = "\x1b[1m"
+	ansiRed    = "\x1b[31m"
+	ansiYellow = "\x1b[33m"
+	ansiBlue   = "\x1b[34m"
+	ansiCyan   = "\x1b[36m"
+)
+
+// tabWidth is the number of columns a tab is expanded to in excerpts.
+const tabWidth = 4
+
+// Renderer is a Reporter that prints each diagnostic with an excerpt of the
+// offending source, underlining the primary span with carets and any labels with dashes.
+type Renderer struct {
+	w io.Writer
+	// Color enables ANSI colours; NewRenderer sets it when w is a terminal.
+	Color bool
+	// Source returns the text of filename, or nil when it is unavailable.
+	Source func(filename string) []byte
+}
+
+// NewRenderer returns a pointer to a Renderer writing to w. Source text is taken from
+// fset when the file is registered there, and otherwise read from disk once per file.
+func NewRenderer(w io.Writer, fset *token.FileSet) *Renderer {
+	cache := map[string][]byte{}
+	source := func(filename string) []byte {
+		if fset != nil {
+			if f := fset.Lookup(filename); f != nil && f.Source() != nil {
+				return f.Source()
+			}
+		}
+		if filename == "" {
+			return nil
+		}
+		src, ok := cache[filename]
+		if !ok {
+			src, _ = ioutil.ReadFile(filename)
+			cache[filename] = src
+		}
+		return src
+	}
+	return &Renderer{w: w, Color: isTerminal(w), Source: source}
+}
+
+// isTerminal reports whether w is a character device, and colour has not been disabled with NO_COLOR.
+func isTerminal(w io.Writer) bool {
+	if os.Getenv("NO_COLOR") != "" {
+		return false
+	}
+	f, ok := w.(*os.File)
+	if !ok {
+		return false
+	}
+	fi, err := f.Stat()
+	return err == nil && fi.Mode()&os.ModeCharDevice != 0
+}
+
+// underline marks the columns [from, to) of one source line.
+type underline struct {
+	line    int
+	from    int
+	to      int
+	primary bool
+	message string
+}
+
+// Report renders d to the underlying writer.
+func (r *Renderer) Report(d Diagnostic) {
+	severity := d.Severity.String()
+	if d.Code != "" {
+		severity += "[" + d.Code + "]"
+	}
+	fmt.Fprintf(r.w, "%s: %s\n", r.paint(severity, r.severityColor(d.Severity)), r.paint(d.Message, ansiBold))
+
+	var src []byte
+	if r.Source != nil {
+		src = r.Source(d.Span.Start.Filename)
+	}
+	lines := map[int]string{}
+	var marks []underline
+	if src != nil && d.Span.Start.IsValid() {
+		marks = r.collect(src, d.Span, true, "", lines)
+		for _, l := range d.Labels {
+			if l.Span.Start.IsValid() && l.Span.Start.Filename == d.Span.Start.Filename {
+				marks = append(marks, r.collect(src, l.Span, false, l.Message, lines)...)
+			}
+		}
+	}
+
+	numbers := make([]int, 0, len(lines))
+	for n := range lines {
+		numbers = append(numbers, n)
+	}
+	sort.Ints(numbers)
+	width := 1
+	if len(numbers) > 0 {
+		width = len(strconv.Itoa(numbers[len(numbers)-1]))
+	}
+	gutter := strings.Repeat(" ", width)
+
+	if d.Span.Start.IsValid() || d.Span.Start.Filename != "" {
+		fmt.Fprintf(r.w, "%s%s %s\n", gutter, r.paint("-->", ansiBlue), d.Span.Start)
+	}
+	if len(numbers) > 0 {
+		fmt.Fprintf(r.w, "%s %s\n", gutter, r.paint("|", ansiBlue))
+		for i, n := range numbers {
+			if i > 0 && n > numbers[i-1]+1 {
+				fmt.Fprintf(r.w, "%s\n", r.paint("...", ansiBlue))
+			}
+			text, columns := expandTabs(lines[n])
+			fmt.Fprintf(r.w, "%s %s %s\n", r.paint(fmt.Sprintf("%*d", width, n), ansiBlue), r.paint("|", ansiBlue), text)
+			for _, m := range marks {
+				if m.line == n {
+					r.writeUnderline(gutter, columns, m, d.Severity)
+				}
+			}
+		}
+		fmt.Fprintf(r.w, "%s %s\n", gutter, r.paint("|", ansiBlue))
+	}
+	for _, note := range d.Notes {
+		fmt.Fprintf(r.w, "%s %s %s\n", gutter, r.paint("=", ansiBlue), r.paint("note:", ansiBold)+" "+note)
+	}
+	fmt.Fprintln(r.w)
+}
+
+// collect splits span into one underline per source line, recording the text of each line in lines.
+// A span whose start has no column only names a line, which is recorded without an underline.
+func (r *Renderer) collect(src []byte, span token.Span, primary bool, message string, lines map[int]string) []underline {
+	if span.Start.Column == 0 {
+		if start, ok := lineOffset(src, span.Start.Line); ok {
+			lines[span.Start.Line] = string(src[start:endOfLine(src, start)])
+		}
+		return nil
+	}
+	var marks []underline
+	start, end := span.Start.Offset, span.End.Offset
+	if start > len(src) {
+		return nil
+	}
+	if end < start {
+		end = start
+	}
+	lineStart, line, from := start, span.Start.Line, span.Start.Column-1
+	for lineStart > 0 && src[lineStart-1] != '\n' && src[lineStart-1] != '\r' {
+		lineStart--
+	}
+	if lineStart == 0 && start >= len(token.BOM) && bytes.HasPrefix(src, []byte(token.BOM)) {
+		lineStart = len(token.BOM)
+	}
+	for {
+		lineEnd := endOfLine(src, lineStart)
+		text := string(src[lineStart:lineEnd])
+		lines[line] = text
+		last := end <= lineEnd || lineEnd >= len(src)
+		to := utf8.RuneCountInString(text)
+		if last {
+			to = utf8.RuneCount(src[lineStart:minInt(end, lineEnd)])
+		}
+		if to <= from {
+			to = from + 1
+		}
+		marks = append(marks, underline{line: line, from: from, to: to, primary: primary})
+		if last {
+			marks[len(marks)-1].message = message
+			return marks
+		}
+		// Step over the line terminator; \r\n counts as one.
+		next := lineEnd + 1
+		if src[lineEnd] == '\r' && next < len(src) && src[next] == '\n' {
+			next++
+		}
+		lineStart, line, from = next, line+1, 0
+	}
+}
+
+// lineOffset returns the offset of the start of line in src, counting \n, \r and \r\n as line
+// breaks, and whether src has that many lines.
+func lineOffset(src []byte, line int) (int, bool) {
+	offset := 0
+	for n := 1; n < line; n++ {
+		offset = endOfLine(src, offset)
+		if offset == len(src) {
+			return 0, false
+		}
+		if src[offset] == '\r' && offset+1 < len(src) && src[offset+1] == '\n' {
+			offset++
+		}
+		offset++
+	}
+	if line == 1 && bytes.HasPrefix(src, []byte(token.BOM)) {
+		offset = len(token.BOM)
+	}
+	return offset, line > 0
+}
+
+// endOfLine returns the offset of the line break ending the line that starts at start, or
+// the length of src on the last line.
+func endOfLine(src []byte, start int) int {
+	end := start
+	for end < len(src) && src[end] != '\n' && src[end] != '\r' {
+		end++
+	}
+	return end
+}
+
+// writeUnderline writes the marker row for m beneath its source line.
+func (r *Renderer) writeUnderline(gutter string, columns []int, m underline, severity Severity) {
+	marker, color := "-", ansiBlue
+	if m.primary {
+		marker, color = "^", r.severityColor(severity)
+	}
+	from, to := displayColumn(columns, m.from), displayColumn(columns, m.to)
+	row := strings.Repeat(" ", from) + strings.Repeat(marker, maxInt(to-from, 1))
+	if m.message != "" {
+		row += " " + m.message
+	}
+	fmt.Fprintf(r.w, "%s %s %s\n", gutter, r.paint("|", ansiBlue), r.paint(row, color))
+}
+
+// expandTabs replaces tabs in text with spaces, returning the display column of every rune.
+func expandTabs(text string) (string, []int) {
+	var b strings.Builder
+	columns := []int{}
+	column := 0
+	for _, c := range text {
+		columns = append(columns, column)
+		if c == '\t' {
+			b.WriteString(strings.Repeat(" ", tabWidth))
+			column += tabWidth
+		} else {
+			b.WriteRune(c)
+			column++
+		}
+	}
+	columns = append(columns, column)
+	return b.String(), columns
+}
+
+// displayColumn converts a rune column into a display column, extending past the end of the line.
+func displayColumn(columns []int, column int) int {
+	if column < 0 {
+		return 0
+	}
+	if column < len(columns) {
+		return columns[column]
+	}
+	return columns[len(columns)-1] + column - len(columns) + 1
+}
+
+func (r *Renderer) severityColor(s Severity) string {
+	switch s {
+	case SeverityError:
+		return ansiBold + ansiRed
+	case SeverityWarning:
+		return ansiBold + ansiYellow
+	default:
+		return ansiBold + ansiCyan
+	}
+}
+
+// paint wraps text in the given colour when colour is enabled.
+func (r *Renderer) paint(text string, color string) string {
+	if !r.Color || text == "" {
+		return text
+	}
+	return color + text + ansiReset
+}
+
+func minInt(a, b int) int {
+	if a < b {
+		return a
+	}
+	return b
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
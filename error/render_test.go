@@ -0,0 +1,159 @@
+package error
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+	"xolog/token"
+)
+
+func TestRenderer_Report(t *testing.T) {
+	fset := token.NewFileSet()
+	fset.AddFile("main.xo", []byte("var a = 1;\n\tprint a @ b;\nvar c = \"x\ny\";\n"))
+	pos := func(offset, line, column int) token.Position {
+		return token.Position{Filename: "main.xo", Offset: offset, Line: line, Column: column}
+	}
+
+	tests := []struct {
+		name  string
+		d     Diagnostic
+		color bool
+		want  string
+	}{
+		{
+			name: "Caret under the span, tabs expanded, notes after the excerpt.",
+			d: Diagnostic{
+				Severity: SeverityError,
+				Span:     token.Span{Start: pos(20, 2, 10), End: pos(21, 2, 11)},
+				Code:     "S0001",
+				Message:  "Unexpected character: @",
+				Notes:    []string{"remove it"},
+			},
+			want: "error[S0001]: Unexpected character: @\n" +
+				" --> main.xo:2:10\n" +
+				"  |\n" +
+				"2 |     print a @ b;\n" +
+				"  |             ^\n" +
+				"  |\n" +
+				"  = note: remove it\n\n",
+		},
+		{
+			name: "Secondary labels on other lines, spans across lines.",
+			d: Diagnostic{
+				Severity: SeverityWarning,
+				Span:     token.Span{Start: pos(33, 3, 9), End: pos(38, 4, 3)},
+				Message:  "Multi-line string.",
+				Labels: []Label{
+					{Span: token.Span{Start: pos(4, 1, 5), End: pos(5, 1, 6)}, Message: "first declared here"},
+				},
+			},
+			want: "warning: Multi-line string.\n" +
+				" --> main.xo:3:9\n" +
+				"  |\n" +
+				"1 | var a = 1;\n" +
+				"  |     - first declared here\n" +
+				"...\n" +
+				"3 | var c = \"x\n" +
+				"  |         ^^\n" +
+				"4 | y\";\n" +
+				"  | ^^\n" +
+				"  |\n\n",
+		},
+		{
+			name: "Unknown file renders the header only.",
+			d: Diagnostic{
+				Severity: SeverityNote,
+				Span:     token.Span{Start: token.Position{Filename: "other.xo", Line: 1, Column: 1}},
+				Message:  "Hello.",
+			},
+			want: "note: Hello.\n" +
+				" --> other.xo:1:1\n\n",
+		},
+		{
+			name: "Position without a column shows its line without markers.",
+			d: Diagnostic{
+				Severity: SeverityError,
+				Span:     token.Span{Start: token.Position{Filename: "main.xo", Line: 2}},
+				Message:  "Bad line.",
+			},
+			want: "error: Bad line.\n" +
+				" --> main.xo:2\n" +
+				"  |\n" +
+				"2 |     print a @ b;\n" +
+				"  |\n\n",
+		},
+		{
+			name: "Position without a column past the last line renders the header only.",
+			d: Diagnostic{
+				Severity: SeverityError,
+				Span:     token.Span{Start: token.Position{Filename: "main.xo", Line: 9}},
+				Message:  "Bad line.",
+			},
+			want: "error: Bad line.\n" +
+				" --> main.xo:9\n\n",
+		},
+		{
+			name: "Colour wraps severity, message and markers.",
+			d: Diagnostic{
+				Severity: SeverityError,
+				Message:  "Boom.",
+			},
+			color: true,
+			want:  "\x1b[1m\x1b[31merror\x1b[0m: \x1b[1mBoom.\x1b[0m\n\n",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			r := NewRenderer(&buf, fset)
+			r.Color = tt.color
+			r.Report(tt.d)
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Renderer.Report() wrote\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderer_Report_readsSourceFromDisk(t *testing.T) {
+	f, err := ioutil.TempFile("", "render-*.xo")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(f.Name())
+	f.WriteString("print @;\n")
+	f.Close()
+
+	buf := bytes.Buffer{}
+	NewRenderer(&buf, nil).Report(Diagnostic{
+		Severity: SeverityError,
+		Span: token.Span{
+			Start: token.Position{Filename: f.Name(), Offset: 6, Line: 1, Column: 7},
+			End:   token.Position{Filename: f.Name(), Offset: 7, Line: 1, Column: 8},
+		},
+		Message: "Unexpected character: @",
+	})
+	if want := "1 | print @;\n  |       ^\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Renderer.Report() wrote\n%s\nwant it to contain\n%s", buf.String(), want)
+	}
+}
+
+func TestRenderer_Report_byteOrderMark(t *testing.T) {
+	fset := token.NewFileSet()
+	fset.AddFile("bom.xo", []byte("\uFEFFprint @;\n"))
+
+	buf := bytes.Buffer{}
+	NewRenderer(&buf, fset).Report(Diagnostic{
+		Severity: SeverityError,
+		Span: token.Span{
+			Start: token.Position{Filename: "bom.xo", Offset: 9, Line: 1, Column: 7},
+			End:   token.Position{Filename: "bom.xo", Offset: 10, Line: 1, Column: 8},
+		},
+		Message: "Unexpected character: @",
+	})
+	if want := "1 | print @;\n  |       ^\n"; !strings.Contains(buf.String(), want) {
+		t.Errorf("Renderer.Report() wrote\n%s\nwant it to contain\n%s", buf.String(), want)
+	}
+}
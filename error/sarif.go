@@ -0,0 +1,154 @@
+package error
+
+import (
+	"encoding/json"
+	"io"
+	"path/filepath"
+	"sort"
+	"xolog/token"
+)
+
+// SARIF 2.1.0 log shapes; only the properties xolog fills in are modelled.
+// See https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+type (
+	sarifLog struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+	sarifRun struct {
+		Tool       sarifTool     `json:"tool"`
+		ColumnKind string        `json:"columnKind"`
+		Results    []sarifResult `json:"results"`
+	}
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+	sarifDriver struct {
+		Name           string      `json:"name"`
+		InformationURI string      `json:"informationUri"`
+		Rules          []sarifRule `json:"rules"`
+	}
+	sarifRule struct {
+		ID string `json:"id"`
+	}
+	sarifResult struct {
+		RuleID           string                 `json:"ruleId,omitempty"`
+		Level            string                 `json:"level"`
+		Message          sarifMessage           `json:"message"`
+		Locations        []sarifLocation        `json:"locations,omitempty"`
+		RelatedLocations []sarifLocation        `json:"relatedLocations,omitempty"`
+		Properties       map[string]interface{} `json:"properties,omitempty"`
+	}
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+	sarifLocation struct {
+		ID               *int                  `json:"id,omitempty"`
+		PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+		Message          *sarifMessage         `json:"message,omitempty"`
+	}
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+	// sarifRegion leaves out what a position does not know; SARIF requires lines and
+	// columns to be at least 1.
+	sarifRegion struct {
+		StartLine   int  `json:"startLine"`
+		StartColumn int  `json:"startColumn,omitempty"`
+		EndLine     int  `json:"endLine,omitempty"`
+		EndColumn   int  `json:"endColumn,omitempty"`
+		ByteOffset  *int `json:"byteOffset,omitempty"`
+		ByteLength  *int `json:"byteLength,omitempty"`
+	}
+)
+
+// sarifLevels maps severities onto SARIF result levels.
+var sarifLevels = [...]string{
+	SeverityError:   "error",
+	SeverityWarning: "warning",
+	SeverityNote:    "note",
+}
+
+// WriteSARIF writes diagnostics to w as a SARIF 2.1.0 log produced by the named tool.
+func WriteSARIF(w io.Writer, tool string, diagnostics List) error {
+	codes := map[string]bool{}
+	results := []sarifResult{}
+	for _, d := range diagnostics {
+		if d.Code != "" {
+			codes[d.Code] = true
+		}
+		level := "none"
+		if d.Severity >= 0 && int(d.Severity) < len(sarifLevels) {
+			level = sarifLevels[d.Severity]
+		}
+		result := sarifResult{RuleID: d.Code, Level: level, Message: sarifMessage{Text: d.Message}}
+		if d.Span.Start.IsValid() {
+			result.Locations = []sarifLocation{sarifLocationFor(d.Span)}
+		}
+		for i, l := range d.Labels {
+			id := i
+			loc := sarifLocationFor(l.Span)
+			loc.ID = &id
+			loc.Message = &sarifMessage{Text: l.Message}
+			result.RelatedLocations = append(result.RelatedLocations, loc)
+		}
+		if len(d.Notes) > 0 {
+			result.Properties = map[string]interface{}{"notes": d.Notes}
+		}
+		results = append(results, result)
+	}
+
+	rules := []sarifRule{}
+	for code := range codes {
+		rules = append(rules, sarifRule{ID: code})
+	}
+	sort.Slice(rules, func(i, j int) bool { return rules[i].ID < rules[j].ID })
+
+	log := sarifLog{
+		Version: "2.1.0",
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Runs: []sarifRun{{
+			Tool: sarifTool{Driver: sarifDriver{
+				Name:           tool,
+				InformationURI: "https://github.com/karn09/xolog",
+				Rules:          rules,
+			}},
+			// Scanner columns count runes, not the UTF-16 code units SARIF assumes by default.
+			ColumnKind: "unicodeCodePoints",
+			Results:    results,
+		}},
+	}
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(log)
+}
+
+// sarifLocationFor returns the location of span. Without a line there is no region, and
+// without a column there are neither columns nor byte offsets.
+func sarifLocationFor(span token.Span) sarifLocation {
+	loc := sarifLocation{
+		PhysicalLocation: sarifPhysicalLocation{
+			ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(span.Start.Filename)},
+		},
+	}
+	if !span.Start.IsValid() {
+		return loc
+	}
+	end := span.End
+	if !end.IsValid() {
+		end = span.Start
+	}
+	region := &sarifRegion{StartLine: span.Start.Line, EndLine: end.Line}
+	if span.Start.Column > 0 {
+		offset, length := span.Start.Offset, end.Offset-span.Start.Offset
+		region.StartColumn, region.EndColumn = span.Start.Column, end.Column
+		region.ByteOffset, region.ByteLength = &offset, &length
+	}
+	loc.PhysicalLocation.Region = region
+	return loc
+}
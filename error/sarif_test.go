@@ -0,0 +1,88 @@
+package error
+
+import (
+	"bytes"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"xolog/token"
+)
+
+func TestWriteSARIF(t *testing.T) {
+	span := func(offset, column, length int) token.Span {
+		return token.Span{
+			Start: token.Position{Filename: "dir/a.xo", Offset: offset, Line: 2, Column: column},
+			End:   token.Position{Filename: "dir/a.xo", Offset: offset + length, Line: 2, Column: column + length},
+		}
+	}
+	diagnostics := List{
+		{Severity: SeverityError, Span: span(10, 3, 1), Code: "S0002", Message: "second"},
+		{
+			Severity: SeverityWarning,
+			Span:     span(12, 5, 2),
+			Code:     "S0001",
+			Message:  "first",
+			Labels:   []Label{{Span: span(10, 3, 1), Message: "related"}},
+		},
+	}
+	buf := bytes.Buffer{}
+	if err := WriteSARIF(&buf, "xolog", diagnostics); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var got sarifLog
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	if got.Version != "2.1.0" || len(got.Runs) != 1 {
+		t.Fatalf("WriteSARIF() version %q with %d runs, want 2.1.0 with 1 run", got.Version, len(got.Runs))
+	}
+	run := got.Runs[0]
+	if want := []sarifRule{{ID: "S0001"}, {ID: "S0002"}}; !reflect.DeepEqual(run.Tool.Driver.Rules, want) {
+		t.Errorf("rules = %v, want %v", run.Tool.Driver.Rules, want)
+	}
+	if len(run.Results) != 2 {
+		t.Fatalf("WriteSARIF() wrote %d results, want 2", len(run.Results))
+	}
+	result := run.Results[1]
+	if result.RuleID != "S0001" || result.Level != "warning" || result.Message.Text != "first" {
+		t.Errorf("result = %+v, want S0001 warning \"first\"", result)
+	}
+	offset, length := 12, 2
+	wantRegion := &sarifRegion{StartLine: 2, StartColumn: 5, EndLine: 2, EndColumn: 7, ByteOffset: &offset, ByteLength: &length}
+	if got := result.Locations[0].PhysicalLocation; !reflect.DeepEqual(got.Region, wantRegion) || got.ArtifactLocation.URI != "dir/a.xo" {
+		t.Errorf("location = %+v, want %v in dir/a.xo", got, wantRegion)
+	}
+	if len(result.RelatedLocations) != 1 || result.RelatedLocations[0].Message.Text != "related" {
+		t.Errorf("relatedLocations = %+v, want one labelled \"related\"", result.RelatedLocations)
+	}
+}
+
+func TestWriteSARIF_noColumn(t *testing.T) {
+	diagnostics := List{
+		{Severity: SeverityError, Span: token.Span{Start: token.Position{Filename: "a.xo", Line: 3}}, Message: "line only"},
+	}
+	buf := bytes.Buffer{}
+	if err := WriteSARIF(&buf, "xolog", diagnostics); err != nil {
+		t.Fatalf("WriteSARIF() error = %v", err)
+	}
+
+	var got struct {
+		Runs []struct {
+			Results []struct {
+				Locations []struct {
+					PhysicalLocation struct {
+						Region map[string]int `json:"region"`
+					} `json:"physicalLocation"`
+				} `json:"locations"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+		t.Fatalf("json.Unmarshal() error = %v", err)
+	}
+	region := got.Runs[0].Results[0].Locations[0].PhysicalLocation.Region
+	if want := map[string]int{"startLine": 3, "endLine": 3}; !reflect.DeepEqual(region, want) {
+		t.Errorf("region = %v, want %v", region, want)
+	}
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"xolog/highlight"
+)
+
+// highlightCommand will implement `xolog highlight`, writing a script, or stdin when no
+// script is given, highlighted as ANSI, HTML or LaTeX.
+func highlightCommand(args []string) {
+	flags := flag.NewFlagSet("highlight", flag.ExitOnError)
+	format := flags.String("format", "ansi", "output `format`: ansi, html or latex")
+	theme := flags.String("theme", "dark", "colour `theme`: "+strings.Join(highlight.ThemeNames(), " or "))
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: xolog [flags] highlight [highlight flags] [script]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	if flags.NArg() > 1 {
+		flags.Usage()
+		os.Exit(64)
+	}
+	t, ok := highlight.Themes[*theme]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown theme %q\n", *theme)
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	name := "<stdin>"
+	var src []byte
+	var err error
+	if path := flags.Arg(0); path != "" && path != "-" {
+		name = path
+		src, err = ioutil.ReadFile(path)
+	} else {
+		src, err = ioutil.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	segments := highlight.Source(string(src))
+	out := bufio.NewWriter(os.Stdout)
+	switch *format {
+	case "ansi":
+		err = highlight.WriteANSI(out, segments, t)
+	case "html":
+		err = highlight.WriteHTML(out, segments, t, name)
+	case "latex":
+		err = highlight.WriteLaTeX(out, segments, t)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown highlight format %q\n", *format)
+		flags.Usage()
+		os.Exit(64)
+	}
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
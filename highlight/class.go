@@ -0,0 +1,112 @@
+package highlight
+
+import (
+	"fmt"
+	"xolog/token"
+)
+
+// Class is the highlighting category of a run of source text.
+type Class int
+
+const (
+	Plain Class = iota
+	Keyword
+	Constant
+	Identifier
+	String
+	Number
+	Operator
+	Punctuation
+	Comment
+	DocComment
+	Illegal
+)
+
+var classNames = [...]string{
+	Plain:       "plain",
+	Keyword:     "keyword",
+	Constant:    "constant",
+	Identifier:  "identifier",
+	String:      "string",
+	Number:      "number",
+	Operator:    "operator",
+	Punctuation: "punctuation",
+	Comment:     "comment",
+	DocComment:  "doc-comment",
+	Illegal:     "illegal",
+}
+
+// String returns the name of the class, which is also its CSS class.
+func (c Class) String() string {
+	if c >= 0 && int(c) < len(classNames) {
+		return classNames[c]
+	}
+	return fmt.Sprintf("class(%d)", int(c))
+}
+
+// ClassOf returns the class tokens of type t are highlighted with.
+func ClassOf(t token.TokenType) Class {
+	switch t {
+	case token.TRUE, token.FALSE, token.NIL:
+		return Constant
+	case token.AND, token.CLASS, token.ELSE, token.FUN, token.FOR, token.IF, token.OR, token.PRINT,
+		token.RETURN, token.SUPER, token.THIS, token.VAR, token.WHILE:
+		return Keyword
+	case token.IDENTIFIER:
+		return Identifier
+	case token.STRING:
+		return String
+	case token.NUMBER:
+		return Number
+	case token.LEFT_PAREN, token.RIGHT_PAREN, token.LEFT_BRACE, token.RIGHT_BRACE, token.COMMA,
+		token.DOT, token.SEMICOLON:
+		return Punctuation
+	case token.DOC_COMMENT:
+		return DocComment
+	case token.ILLEGAL:
+		return Illegal
+	case token.EOF:
+		return Plain
+	}
+	return Operator
+}
+
+// Segment is a run of source text highlighted with one class.
+type Segment struct {
+	Class Class
+	Text  string
+}
+
+// Segments splits tokens scanned with scanner.ScanTrivia into highlighted segments, whose
+// texts concatenate to the scanned source. Comment trivia is highlighted as Comment, and
+// other trivia is Plain.
+func Segments(tokens []token.Token) []Segment {
+	var segments []Segment
+	add := func(class Class, text string) {
+		if text == "" {
+			return
+		}
+		if n := len(segments); n > 0 && segments[n-1].Class == class {
+			segments[n-1].Text += text
+			return
+		}
+		segments = append(segments, Segment{Class: class, Text: text})
+	}
+	addTrivia := func(trivia []token.Trivia) {
+		for _, t := range trivia {
+			if t.Kind == token.COMMENT {
+				add(Comment, t.Text)
+			} else {
+				add(Plain, t.Text)
+			}
+		}
+	}
+	for _, tok := range tokens {
+		addTrivia(tok.LeadingTrivia)
+		if tok.Type != token.EOF {
+			add(ClassOf(tok.Type), tok.Lexeme)
+		}
+		addTrivia(tok.TrailingTrivia)
+	}
+	return segments
+}
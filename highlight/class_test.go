@@ -0,0 +1,72 @@
+package highlight
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"xolog/token"
+)
+
+func TestClassOf(t *testing.T) {
+	tests := []struct {
+		tokenType token.TokenType
+		want      Class
+	}{
+		{tokenType: token.VAR, want: Keyword},
+		{tokenType: token.NIL, want: Constant},
+		{tokenType: token.IDENTIFIER, want: Identifier},
+		{tokenType: token.STRING, want: String},
+		{tokenType: token.NUMBER, want: Number},
+		{tokenType: token.PIPE_GREATER, want: Operator},
+		{tokenType: token.SEMICOLON, want: Punctuation},
+		{tokenType: token.DOC_COMMENT, want: DocComment},
+		{tokenType: token.ILLEGAL, want: Illegal},
+	}
+	for _, tt := range tests {
+		t.Run(tt.tokenType.String(), func(t *testing.T) {
+			if got := ClassOf(tt.tokenType); got != tt.want {
+				t.Errorf("ClassOf() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSource(t *testing.T) {
+	src := "/// Doc.\nvar a = \"x\"; // note\n  print a + 1 @\n"
+	got := Source(src)
+	want := []Segment{
+		{Class: DocComment, Text: "/// Doc."},
+		{Class: Plain, Text: "\n"},
+		{Class: Keyword, Text: "var"},
+		{Class: Plain, Text: " "},
+		{Class: Identifier, Text: "a"},
+		{Class: Plain, Text: " "},
+		{Class: Operator, Text: "="},
+		{Class: Plain, Text: " "},
+		{Class: String, Text: `"x"`},
+		{Class: Punctuation, Text: ";"},
+		{Class: Plain, Text: " "},
+		{Class: Comment, Text: "// note"},
+		{Class: Plain, Text: "\n  "},
+		{Class: Keyword, Text: "print"},
+		{Class: Plain, Text: " "},
+		{Class: Identifier, Text: "a"},
+		{Class: Plain, Text: " "},
+		{Class: Operator, Text: "+"},
+		{Class: Plain, Text: " "},
+		{Class: Number, Text: "1"},
+		{Class: Plain, Text: " "},
+		{Class: Illegal, Text: "@"},
+		{Class: Plain, Text: "\n"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Source() = %v, want %v", got, want)
+	}
+	var text strings.Builder
+	for _, s := range got {
+		text.WriteString(s.Text)
+	}
+	if text.String() != src {
+		t.Errorf("segment text = %q, want %q", text.String(), src)
+	}
+}
@@ -0,0 +1,15 @@
+package highlight
+
+import (
+	"xolog/error"
+	"xolog/scanner"
+)
+
+// Source scans src and returns its highlighted segments. Scanning errors do not stop
+// highlighting; the offending text is highlighted as Illegal, and no diagnostics are reported.
+func Source(src string) []Segment {
+	s := scanner.NewScanner(src)
+	s.Mode = scanner.ScanTrivia
+	s.Reporter = error.ReporterFunc(func(error.Diagnostic) {})
+	return Segments(s.ScanTokens())
+}
@@ -0,0 +1,91 @@
+package highlight
+
+import (
+	"fmt"
+	"sort"
+)
+
+// Style is how text of one class is displayed. Colours are written as #rrggbb, and an
+// empty Color keeps the theme's foreground.
+type Style struct {
+	Color     string
+	Bold      bool
+	Italic    bool
+	Underline bool
+}
+
+// Theme assigns a Style to each class. Classes without a style use the foreground colour.
+type Theme struct {
+	Name       string
+	Background string
+	Foreground string
+	Styles     map[Class]Style
+}
+
+// Light is a theme for light backgrounds.
+var Light = &Theme{
+	Name:       "light",
+	Background: "#ffffff",
+	Foreground: "#24292e",
+	Styles: map[Class]Style{
+		Keyword:    {Color: "#d73a49", Bold: true},
+		Constant:   {Color: "#005cc5"},
+		String:     {Color: "#032f62"},
+		Number:     {Color: "#005cc5"},
+		Operator:   {Color: "#d73a49"},
+		Comment:    {Color: "#6a737d", Italic: true},
+		DocComment: {Color: "#22863a", Italic: true},
+		Illegal:    {Color: "#b31d28", Underline: true},
+	},
+}
+
+// Dark is a theme for dark backgrounds.
+var Dark = &Theme{
+	Name:       "dark",
+	Background: "#282c34",
+	Foreground: "#abb2bf",
+	Styles: map[Class]Style{
+		Keyword:    {Color: "#c678dd", Bold: true},
+		Constant:   {Color: "#d19a66"},
+		String:     {Color: "#98c379"},
+		Number:     {Color: "#d19a66"},
+		Operator:   {Color: "#56b6c2"},
+		Comment:    {Color: "#5c6370", Italic: true},
+		DocComment: {Color: "#7f848e", Italic: true},
+		Illegal:    {Color: "#e06c75", Underline: true},
+	},
+}
+
+// Themes holds the built in themes by name.
+var Themes = map[string]*Theme{
+	Light.Name: Light,
+	Dark.Name:  Dark,
+}
+
+// ThemeNames returns the names of the built in themes in order.
+func ThemeNames() []string {
+	names := make([]string, 0, len(Themes))
+	for name := range Themes {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// style returns the style of class, with the foreground filled in when it has no colour.
+func (t *Theme) style(class Class) Style {
+	s := t.Styles[class]
+	if s.Color == "" {
+		s.Color = t.Foreground
+	}
+	return s
+}
+
+// rgb splits a #rrggbb colour into its components.
+func rgb(color string) (r, g, b int, ok bool) {
+	if len(color) != 7 || color[0] != '#' {
+		return 0, 0, 0, false
+	}
+	_, err := fmt.Sscanf(color[1:], "%02x%02x%02x", &r, &g, &b)
+	return r, g, b, err == nil
+}
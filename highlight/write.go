@@ -0,0 +1,153 @@
+package highlight
+
+import (
+	"fmt"
+	"html"
+	"io"
+	"strings"
+)
+
+// WriteANSI writes segments with ANSI escape sequences for terminals with 24-bit colour.
+func WriteANSI(w io.Writer, segments []Segment, theme *Theme) error {
+	var b strings.Builder
+	for _, s := range segments {
+		codes := ansiCodes(theme.Styles[s.Class])
+		if codes == "" {
+			b.WriteString(s.Text)
+			continue
+		}
+		// Styles are reset before each newline so that pagers showing a single line keep their colours.
+		for i, line := range strings.Split(s.Text, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if line != "" {
+				b.WriteString("\x1b[" + codes + "m" + line + "\x1b[0m")
+			}
+		}
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// ansiCodes returns the SGR parameters for style, or "" when it changes nothing.
+func ansiCodes(style Style) string {
+	var codes []string
+	if style.Bold {
+		codes = append(codes, "1")
+	}
+	if style.Italic {
+		codes = append(codes, "3")
+	}
+	if style.Underline {
+		codes = append(codes, "4")
+	}
+	if r, g, b, ok := rgb(style.Color); ok {
+		codes = append(codes, fmt.Sprintf("38;2;%d;%d;%d", r, g, b))
+	}
+	return strings.Join(codes, ";")
+}
+
+// WriteHTML writes segments as a standalone HTML document titled title. Each segment is
+// a span whose CSS class is its class name, styled by a stylesheet generated from theme.
+func WriteHTML(w io.Writer, segments []Segment, theme *Theme, title string) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"utf-8\">\n")
+	fmt.Fprintf(&b, "<title>%s</title>\n<style>\n", html.EscapeString(title))
+	fmt.Fprintf(&b, "pre.xolog { background: %s; color: %s; padding: 1em; }\n", theme.Background, theme.Foreground)
+	for class := range classNames {
+		style, ok := theme.Styles[Class(class)]
+		if !ok {
+			continue
+		}
+		fmt.Fprintf(&b, "pre.xolog .%s { %s}\n", Class(class), css(theme, style))
+	}
+	b.WriteString("</style>\n</head>\n<body>\n<pre class=\"xolog\">")
+	for _, s := range segments {
+		text := html.EscapeString(s.Text)
+		if s.Class == Plain {
+			b.WriteString(text)
+		} else {
+			fmt.Fprintf(&b, "<span class=\"%s\">%s</span>", s.Class, text)
+		}
+	}
+	b.WriteString("</pre>\n</body>\n</html>\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// css returns the declarations for style.
+func css(theme *Theme, style Style) string {
+	color := style.Color
+	if color == "" {
+		color = theme.Foreground
+	}
+	declarations := "color: " + color + "; "
+	if style.Bold {
+		declarations += "font-weight: bold; "
+	}
+	if style.Italic {
+		declarations += "font-style: italic; "
+	}
+	if style.Underline {
+		declarations += "text-decoration: underline; "
+	}
+	return declarations
+}
+
+// WriteLaTeX writes segments as a standalone LaTeX document, setting the source in a fancyvrb
+// Verbatim environment with one \XL macro per class defined from theme.
+func WriteLaTeX(w io.Writer, segments []Segment, theme *Theme) error {
+	var b strings.Builder
+	b.WriteString("\\documentclass{article}\n\\usepackage[T1]{fontenc}\n\\usepackage{xcolor}\n\\usepackage{fancyvrb}\n")
+	b.WriteString("\\def\\XLbs{\\char`\\\\}\n\\def\\XLob{\\char`\\{}\n\\def\\XLcb{\\char`\\}}\n")
+	writeLaTeXColor(&b, "background", theme.Background)
+	for class := range classNames {
+		style := theme.style(Class(class))
+		name := latexName(Class(class))
+		writeLaTeXColor(&b, name, style.Color)
+		body := "\\textcolor{XL" + name + "}{#1}"
+		if style.Bold {
+			body = "\\textbf{" + body + "}"
+		}
+		if style.Italic {
+			body = "\\textit{" + body + "}"
+		}
+		if style.Underline {
+			body = "\\underline{" + body + "}"
+		}
+		fmt.Fprintf(&b, "\\newcommand{\\XL%s}[1]{%s}\n", name, body)
+	}
+	b.WriteString("\\begin{document}\n\\pagecolor{XLbackground}\n\\begin{Verbatim}[commandchars=\\\\\\{\\}]\n")
+	for _, s := range segments {
+		// Macro arguments cannot span lines inside Verbatim, so each line is wrapped on its own.
+		for i, line := range strings.Split(s.Text, "\n") {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			if line != "" {
+				fmt.Fprintf(&b, "\\XL%s{%s}", latexName(s.Class), latexEscape(line))
+			}
+		}
+	}
+	if n := len(segments); n == 0 || !strings.HasSuffix(segments[n-1].Text, "\n") {
+		b.WriteString("\n")
+	}
+	b.WriteString("\\end{Verbatim}\n\\end{document}\n")
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// latexName returns the class name as letters only, so that it can be part of a macro name.
+func latexName(class Class) string {
+	return strings.Replace(class.String(), "-", "", -1)
+}
+
+func writeLaTeXColor(b *strings.Builder, name string, color string) {
+	if r, g, bl, ok := rgb(color); ok {
+		fmt.Fprintf(b, "\\definecolor{XL%s}{RGB}{%d,%d,%d}\n", name, r, g, bl)
+	}
+}
+
+// latexEscape replaces the characters Verbatim treats as commands with macros that print them.
+var latexEscape = strings.NewReplacer("\\", "\\XLbs{}", "{", "\\XLob{}", "}", "\\XLcb{}").Replace
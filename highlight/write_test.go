@@ -0,0 +1,67 @@
+package highlight
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+var testSegments = []Segment{
+	{Class: Keyword, Text: "print"},
+	{Class: Plain, Text: " "},
+	{Class: String, Text: `"<a & {b}>\n"`},
+	{Class: Punctuation, Text: ";"},
+	{Class: Plain, Text: " "},
+	{Class: Comment, Text: "/* x\ny */"},
+}
+
+func TestWriteANSI(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteANSI(&b, testSegments, Dark); err != nil {
+		t.Fatalf("WriteANSI() error = %v", err)
+	}
+	want := "\x1b[1;38;2;198;120;221mprint\x1b[0m " +
+		"\x1b[38;2;152;195;121m\"<a & {b}>\\n\"\x1b[0m; " +
+		"\x1b[3;38;2;92;99;112m/* x\x1b[0m\n\x1b[3;38;2;92;99;112my */\x1b[0m"
+	if got := b.String(); got != want {
+		t.Errorf("WriteANSI() = %q, want %q", got, want)
+	}
+}
+
+func TestWriteHTML(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteHTML(&b, testSegments, Light, "a<b>.xlg"); err != nil {
+		t.Fatalf("WriteHTML() error = %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"<title>a&lt;b&gt;.xlg</title>",
+		"pre.xolog { background: #ffffff; color: #24292e; padding: 1em; }",
+		"pre.xolog .keyword { color: #d73a49; font-weight: bold; }",
+		`<pre class="xolog"><span class="keyword">print</span> <span class="string">&#34;&lt;a &amp; {b}&gt;\n&#34;</span><span class="punctuation">;</span> <span class="comment">/* x` + "\n" + `y */</span></pre>`,
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteHTML() = %s, want it to contain %s", got, want)
+		}
+	}
+}
+
+func TestWriteLaTeX(t *testing.T) {
+	var b bytes.Buffer
+	if err := WriteLaTeX(&b, testSegments, Light); err != nil {
+		t.Fatalf("WriteLaTeX() error = %v", err)
+	}
+	got := b.String()
+	for _, want := range []string{
+		"\\definecolor{XLkeyword}{RGB}{215,58,73}",
+		"\\newcommand{\\XLkeyword}[1]{\\textbf{\\textcolor{XLkeyword}{#1}}}",
+		"\\newcommand{\\XLdoccomment}[1]{\\textit{\\textcolor{XLdoccomment}{#1}}}",
+		"\\begin{Verbatim}[commandchars=\\\\\\{\\}]\n" +
+			"\\XLkeyword{print}\\XLplain{ }\\XLstring{\"<a & \\XLob{}b\\XLcb{}>\\XLbs{}n\"}\\XLpunctuation{;}\\XLplain{ }\\XLcomment{/* x}\n\\XLcomment{y */}\n" +
+			"\\end{Verbatim}",
+	} {
+		if !strings.Contains(got, want) {
+			t.Errorf("WriteLaTeX() = %s, want it to contain %s", got, want)
+		}
+	}
+}
@@ -0,0 +1,380 @@
+package parser
+
+import (
+	"fmt"
+	"sort"
+	"xolog/ast"
+	"xolog/error"
+	"xolog/token"
+)
+
+// Diagnostic codes reported by the Parser.
+const (
+	CodeExpectedExpression = "P0001"
+	CodeExpectedToken      = "P0002"
+	CodeInvalidAssignment  = "P0003"
+	CodeTooManyArguments   = "P0004"
+	CodeTooManyParameters  = "P0005"
+)
+
+// maxArguments is the largest number of arguments a call may pass, and of parameters a
+// function may declare.
+const maxArguments = 255
+
+// bailout is raised with panic to abandon the current statement after a syntax error.
+type bailout struct{}
+
+type Parser struct {
+	tokens   []token.Token
+	current  int
+	previous token.Token    // the last token consumed
+	doc      []token.Token  // DOC_COMMENT tokens skipped since previous
+	reported int            // offset of the last error reported, or -1
+	limit    error.Reporter // Reporter wrapped by error.Limit, once MaxErrors is in force
+	HadError bool
+	// Reporter receives diagnostics as they are found; nil reports to error.Default.
+	Reporter error.Reporter
+	// MaxErrors, when positive, is passed to error.Limit to cap the errors the Reporter sees.
+	MaxErrors int
+}
+
+// NewParser accepts tokens as returned by scanner.ScanTokens, and returns a pointer to the
+// initialized Parser struct. DOC_COMMENT tokens are only kept as the Doc of declarations.
+// ILLEGAL tokens have already been reported by the Scanner, so they parse as BadExpr
+// operands, and syntax errors at or just after them are not reported again.
+func NewParser(tokens []token.Token) *Parser {
+	if n := len(tokens); n == 0 || tokens[n-1].Type != token.EOF {
+		eof := token.Token{Type: token.EOF, Lexeme: string('\000')}
+		if n > 0 {
+			end := tokens[n-1].Span.End
+			eof.Line, eof.Span = end.Line, token.Span{Start: end, End: end}
+		}
+		tokens = append(tokens[:n:n], eof)
+	}
+	p := &Parser{tokens: tokens, reported: -1}
+	p.skip()
+	return p
+}
+
+// ParseProgram will parse tokens as a whole program, and return it with every diagnostic found.
+func ParseProgram(tokens []token.Token) (*ast.Program, error.List) {
+	diagnostics := error.List{}
+	p := NewParser(tokens)
+	p.Reporter = &diagnostics
+	return p.Parse(), diagnostics
+}
+
+// Parse will parse the tokens as a whole program. After a syntax error it skips to the
+// next statement boundary and carries on, so that every error is reported; the skipped
+// tokens become a BadStmt, and missing operands a BadExpr.
+func (p *Parser) Parse() *ast.Program {
+	program := &ast.Program{}
+	for !p.isAtEnd() {
+		program.Statements = append(program.Statements, p.declaration())
+	}
+	program.EOF = p.peek()
+	return program
+}
+
+// ParseExpression will parse the tokens as a single expression. A syntax error that leaves
+// no expression to return yields a BadExpr spanning the tokens read; tokens left over after
+// the expression are reported.
+func (p *Parser) ParseExpression() (expr ast.Expr) {
+	start := p.current
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			expr = &ast.BadExpr{From: p.tokens[start].Span.Start, To: p.endSince(start)}
+		}
+	}()
+	expr = p.expression()
+	if !p.isAtEnd() {
+		p.errorAt(p.peek(), CodeExpectedToken, "Expected end of expression, found %s.", describe(p.peek()))
+	}
+	return expr
+}
+
+// synchronize will skip tokens after a syntax error until a statement boundary: past a
+// semicolon, or before a keyword that begins a statement. At least one token is skipped
+// unless some were consumed since start, so that parsing always makes progress.
+func (p *Parser) synchronize(start int) {
+	if p.current == start {
+		p.advance()
+	}
+	for !p.isAtEnd() {
+		if p.previous.Type == token.SEMICOLON && p.current > start {
+			return
+		}
+		switch p.peek().Type {
+		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN:
+			return
+		}
+		p.advance()
+	}
+}
+
+// endSince will return the end of the last token consumed since the token at start, or
+// the start of that token when none was.
+func (p *Parser) endSince(start int) token.Position {
+	if p.current == start {
+		return p.tokens[start].Span.Start
+	}
+	return p.previous.Span.End
+}
+
+// expression → assignment
+func (p *Parser) expression() ast.Expr {
+	return p.assignment()
+}
+
+// assignment → ( call "." )? IDENTIFIER "=" assignment | logicOr
+func (p *Parser) assignment() ast.Expr {
+	expr := p.logicOr()
+	if p.match(token.EQUAL) {
+		equals := p.previous
+		value := p.assignment()
+		switch target := expr.(type) {
+		case *ast.Variable:
+			return &ast.Assign{Name: target.Name, Value: value}
+		case *ast.Get:
+			return &ast.Set{Object: target.Object, Name: target.Name, Value: value}
+		}
+		p.errorAt(equals, CodeInvalidAssignment, "Invalid assignment target.")
+	}
+	return expr
+}
+
+// logicOr → logicAnd ( "or" logicAnd )*
+func (p *Parser) logicOr() ast.Expr {
+	expr := p.logicAnd()
+	for p.match(token.OR) {
+		operator := p.previous
+		expr = &ast.Logical{Left: expr, Operator: operator, Right: p.logicAnd()}
+	}
+	return expr
+}
+
+// logicAnd → equality ( "and" equality )*
+func (p *Parser) logicAnd() ast.Expr {
+	expr := p.equality()
+	for p.match(token.AND) {
+		operator := p.previous
+		expr = &ast.Logical{Left: expr, Operator: operator, Right: p.equality()}
+	}
+	return expr
+}
+
+// equality → comparison ( ( "!=" | "==" ) comparison )*
+func (p *Parser) equality() ast.Expr {
+	return p.binary(p.comparison, token.BANG_EQUAL, token.EQUAL_EQUAL)
+}
+
+// comparison → term ( ( ">" | ">=" | "<" | "<=" ) term )*
+func (p *Parser) comparison() ast.Expr {
+	return p.binary(p.term, token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL)
+}
+
+// term → factor ( ( "-" | "+" ) factor )*
+func (p *Parser) term() ast.Expr {
+	return p.binary(p.factor, token.MINUS, token.PLUS)
+}
+
+// factor → unary ( ( "/" | "*" ) unary )*
+func (p *Parser) factor() ast.Expr {
+	return p.binary(p.unary, token.SLASH, token.STAR)
+}
+
+// binary will parse a left associative chain of operands joined by any of operators.
+func (p *Parser) binary(operand func() ast.Expr, operators ...token.TokenType) ast.Expr {
+	expr := operand()
+	for p.match(operators...) {
+		operator := p.previous
+		expr = &ast.Binary{Left: expr, Operator: operator, Right: operand()}
+	}
+	return expr
+}
+
+// unary → ( "!" | "-" ) unary | call
+func (p *Parser) unary() ast.Expr {
+	if p.match(token.BANG, token.MINUS) {
+		operator := p.previous
+		return &ast.Unary{Operator: operator, Right: p.unary()}
+	}
+	return p.call()
+}
+
+// call → primary ( "(" arguments? ")" | "." IDENTIFIER )*
+func (p *Parser) call() ast.Expr {
+	expr := p.primary()
+	for {
+		if p.match(token.LEFT_PAREN) {
+			expr = p.finishCall(expr)
+		} else if p.match(token.DOT) {
+			name := p.consume(token.IDENTIFIER, "property name after '.'")
+			expr = &ast.Get{Object: expr, Name: name}
+		} else {
+			return expr
+		}
+	}
+}
+
+// finishCall will parse the arguments of a call of callee, after its opening parenthesis.
+func (p *Parser) finishCall(callee ast.Expr) ast.Expr {
+	var arguments []ast.Expr
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(arguments) == maxArguments {
+				p.errorAt(p.peek(), CodeTooManyArguments, "Can't have more than %d arguments.", maxArguments)
+			}
+			arguments = append(arguments, p.expression())
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	paren := p.consume(token.RIGHT_PAREN, "')' after arguments")
+	return &ast.Call{Callee: callee, Paren: paren, Arguments: arguments}
+}
+
+// primary → NUMBER | STRING | "true" | "false" | "nil" | "this" | IDENTIFIER
+//
+//	| "(" expression ")" | "super" "." IDENTIFIER
+func (p *Parser) primary() ast.Expr {
+	switch {
+	case p.match(token.NUMBER, token.STRING, token.TRUE, token.FALSE, token.NIL):
+		return &ast.Literal{Token: p.previous, Value: p.previous.Literal}
+	case p.match(token.THIS):
+		return &ast.This{Keyword: p.previous}
+	case p.match(token.IDENTIFIER):
+		return &ast.Variable{Name: p.previous}
+	case p.match(token.SUPER):
+		keyword := p.previous
+		p.consume(token.DOT, "'.' after 'super'")
+		method := p.consume(token.IDENTIFIER, "superclass method name")
+		return &ast.Super{Keyword: keyword, Method: method}
+	case p.match(token.LEFT_PAREN):
+		lparen := p.previous
+		expr := p.expression()
+		rparen := p.consume(token.RIGHT_PAREN, "')' after expression")
+		return &ast.Grouping{Lparen: lparen, Expression: expr, Rparen: rparen}
+	case p.match(token.ILLEGAL):
+		p.HadError = true
+		return &ast.BadExpr{From: p.previous.Span.Start, To: p.previous.Span.End}
+	}
+	p.errorAt(p.peek(), CodeExpectedExpression, "Expected expression, found %s.", describe(p.peek()))
+	at := p.peek().Span.Start
+	return &ast.BadExpr{From: at, To: at}
+}
+
+// match will consume the next token if it has any of types.
+func (p *Parser) match(types ...token.TokenType) bool {
+	for _, t := range types {
+		if p.check(t) {
+			p.advance()
+			return true
+		}
+	}
+	return false
+}
+
+// consume will consume the next token if it has type t, and otherwise report that what
+// was expected is missing and abandon the parse.
+func (p *Parser) consume(t token.TokenType, expected string) token.Token {
+	if p.check(t) {
+		return p.advance()
+	}
+	p.errorAt(p.peek(), CodeExpectedToken, "Expected %s, found %s.", expected, describe(p.peek()))
+	panic(bailout{})
+}
+
+// check will return whether the next token has type t.
+func (p *Parser) check(t token.TokenType) bool {
+	return p.peek().Type == t
+}
+
+// advance will consume and return the next token. EOF is never consumed.
+func (p *Parser) advance() token.Token {
+	if !p.isAtEnd() {
+		p.previous = p.tokens[p.current]
+		p.current++
+		p.doc = nil
+		p.skip()
+	}
+	return p.previous
+}
+
+// skip will move past doc comments, collecting them.
+func (p *Parser) skip() {
+	for p.tokens[p.current].Type == token.DOC_COMMENT {
+		p.doc = append(p.doc, p.tokens[p.current])
+		p.current++
+	}
+}
+
+func (p *Parser) isAtEnd() bool {
+	return p.peek().Type == token.EOF
+}
+
+func (p *Parser) peek() token.Token {
+	return p.tokens[p.current]
+}
+
+// describe will name tok for a diagnostic.
+func describe(tok token.Token) string {
+	if tok.Type == token.EOF {
+		return "end of file"
+	}
+	return fmt.Sprintf("'%s'", tok.Lexeme)
+}
+
+// errorAt will report an error diagnostic spanning tok. An error at the same place as the
+// last one is dropped, since it follows from it, as is one at or just after an ILLEGAL token.
+func (p *Parser) errorAt(tok token.Token, code string, format string, args ...interface{}) {
+	if tok.Span.Start.Offset == p.reported {
+		return
+	}
+	p.reported = tok.Span.Start.Offset
+	if p.afterIllegal(tok) {
+		p.HadError = true
+		return
+	}
+	p.report(error.Diagnostic{
+		Severity: error.SeverityError,
+		Span:     tok.Span,
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// afterIllegal will return whether tok is ILLEGAL, or the token before it, not counting
+// doc comments, is.
+func (p *Parser) afterIllegal(tok token.Token) bool {
+	if tok.Type == token.ILLEGAL {
+		return true
+	}
+	i := sort.Search(len(p.tokens), func(i int) bool { return p.tokens[i].Span.Start.Offset >= tok.Span.Start.Offset }) - 1
+	for i >= 0 && p.tokens[i].Type == token.DOC_COMMENT {
+		i--
+	}
+	return i >= 0 && p.tokens[i].Type == token.ILLEGAL
+}
+
+// report will pass d to the Reporter, recording whether an error was seen.
+func (p *Parser) report(d error.Diagnostic) {
+	if d.Severity == error.SeverityError {
+		p.HadError = true
+	}
+	r := p.Reporter
+	if r == nil {
+		r = error.Default
+	}
+	if p.MaxErrors > 0 {
+		if p.limit == nil {
+			p.limit = error.Limit(r, p.MaxErrors)
+		}
+		r = p.limit
+	}
+	r.Report(d)
+}
@@ -0,0 +1,187 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"xolog/ast"
+	"xolog/error"
+	"xolog/scanner"
+	"xolog/token"
+)
+
+// parse will scan and parse src as an expression, and return the parser's diagnostics.
+func parse(src string) (*Parser, ast.Expr, error.List) {
+	s := scanner.NewScanner(src)
+	s.Reporter = error.ReporterFunc(func(error.Diagnostic) {})
+	diagnostics := error.List{}
+	p := NewParser(s.ScanTokens())
+	p.Reporter = &diagnostics
+	return p, p.ParseExpression(), diagnostics
+}
+
+// sexpr will write e as a parenthesized prefix expression.
+func sexpr(e ast.Expr) string {
+	switch e := e.(type) {
+	case nil:
+		return "<nil>"
+	case *ast.Assign:
+		return fmt.Sprintf("(= %s %s)", e.Name.Lexeme, sexpr(e.Value))
+	case *ast.BadExpr:
+		return "(bad)"
+	case *ast.Binary:
+		return fmt.Sprintf("(%s %s %s)", e.Operator.Lexeme, sexpr(e.Left), sexpr(e.Right))
+	case *ast.Call:
+		args := []string{sexpr(e.Callee)}
+		for _, a := range e.Arguments {
+			args = append(args, sexpr(a))
+		}
+		return fmt.Sprintf("(call %s)", strings.Join(args, " "))
+	case *ast.Get:
+		return fmt.Sprintf("(. %s %s)", sexpr(e.Object), e.Name.Lexeme)
+	case *ast.Grouping:
+		return fmt.Sprintf("(group %s)", sexpr(e.Expression))
+	case *ast.Literal:
+		return fmt.Sprintf("%#v", e.Value)
+	case *ast.Logical:
+		return fmt.Sprintf("(%s %s %s)", e.Operator.Lexeme, sexpr(e.Left), sexpr(e.Right))
+	case *ast.Set:
+		return fmt.Sprintf("(= (. %s %s) %s)", sexpr(e.Object), e.Name.Lexeme, sexpr(e.Value))
+	case *ast.Super:
+		return fmt.Sprintf("(super %s)", e.Method.Lexeme)
+	case *ast.This:
+		return "this"
+	case *ast.Unary:
+		return fmt.Sprintf("(%s %s)", e.Operator.Lexeme, sexpr(e.Right))
+	case *ast.Variable:
+		return e.Name.Lexeme
+	}
+	return fmt.Sprintf("%T", e)
+}
+
+func TestParser_ParseExpression(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "Parse literals.", src: `"a" == nil != true`, want: `(!= (== "a" <nil>) true)`},
+		{name: "Parse factor before term.", src: "1 + 2 * 3 - 4 / 5", want: "(- (+ 1 (* 2 3)) (/ 4 5))"},
+		{name: "Parse term before comparison.", src: "a < b + 1 == c >= 2.5", want: "(== (< a (+ b 1)) (>= c 2.5))"},
+		{name: "Parse nested unary operators.", src: "!-x * -1", want: "(* (! (- x)) (- 1))"},
+		{name: "Parse groupings.", src: "(1 + 2) * (3)", want: "(* (group (+ 1 2)) (group 3))"},
+		{name: "Parse logical operators.", src: "a or b and c == d", want: "(or a (and b (== c d)))"},
+		{name: "Parse right associative assignment.", src: "a = b = c or d", want: "(= a (= b (or c d)))"},
+		{name: "Parse calls and property access.", src: "f(1, g())(x).y.z()", want: "(call (. (. (call (call f 1 (call g)) x) y) z))"},
+		{name: "Parse property assignment.", src: "this.a.b = super.c", want: "(= (. (. this a) b) (super c))"},
+		{name: "Skip doc comments.", src: "/// one\n1 +\n/// two\n2", want: "(+ 1 2)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, got, diagnostics := parse(tt.src)
+			if s := sexpr(got); s != tt.want {
+				t.Errorf("ParseExpression() = %v, want %v", s, tt.want)
+			}
+			if p.HadError || len(diagnostics) != 0 {
+				t.Errorf("ParseExpression() reported %v", diagnostics)
+			}
+		})
+	}
+}
+
+func TestParser_ParseExpression_errors(t *testing.T) {
+	tests := []struct {
+		name        string
+		src         string
+		want        string
+		wantCode    string
+		wantMessage string
+		wantColumn  int
+	}{
+		{name: "Report a missing operand.", src: "1 + )", want: "(+ 1 (bad))", wantCode: CodeExpectedExpression, wantMessage: "Expected expression, found ')'.", wantColumn: 5},
+		{name: "Report an empty source.", src: "", want: "(bad)", wantCode: CodeExpectedExpression, wantMessage: "Expected expression, found end of file.", wantColumn: 1},
+		{name: "Report an unclosed group.", src: "(1 + 2", want: "(bad)", wantCode: CodeExpectedToken, wantMessage: "Expected ')' after expression, found end of file.", wantColumn: 7},
+		{name: "Report a missing property name.", src: "a.1", want: "(bad)", wantCode: CodeExpectedToken, wantMessage: "Expected property name after '.', found '1'.", wantColumn: 3},
+		{name: "Report super without a method.", src: "super", want: "(bad)", wantCode: CodeExpectedToken, wantMessage: "Expected '.' after 'super', found end of file.", wantColumn: 6},
+		{name: "Report an invalid assignment target and carry on.", src: "a + b = c", want: "(+ a b)", wantCode: CodeInvalidAssignment, wantMessage: "Invalid assignment target.", wantColumn: 7},
+		{name: "Report tokens after the expression.", src: "1 2", want: "1", wantCode: CodeExpectedToken, wantMessage: "Expected end of expression, found '2'.", wantColumn: 3},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, got, diagnostics := parse(tt.src)
+			if s := sexpr(got); s != tt.want {
+				t.Errorf("ParseExpression() = %v, want %v", s, tt.want)
+			}
+			if !p.HadError {
+				t.Errorf("ParseExpression() did not set HadError")
+			}
+			if len(diagnostics) != 1 {
+				t.Fatalf("ParseExpression() reported %v, want one diagnostic", diagnostics)
+			}
+			d := diagnostics[0]
+			if d.Code != tt.wantCode || d.Message != tt.wantMessage || d.Span.Start.Column != tt.wantColumn {
+				t.Errorf("ParseExpression() reported %v at column %d, want %s: %s at column %d", d, d.Span.Start.Column, tt.wantCode, tt.wantMessage, tt.wantColumn)
+			}
+		})
+	}
+}
+
+func TestParser_illegal(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "Parse a malformed number as a bad operand.", src: "var y = 0x;", want: "(var y (bad))"},
+		{name: "Report nothing more at an illegal token.", src: "print \"hi\" @@ 3;", want: "(bad)"},
+		{name: "Report nothing more just after an illegal token.", src: "print 1 + \\ 2;", want: "(bad)"},
+		{name: "Carry on after an illegal statement.", src: "@;\nprint 1;", want: "(; (bad)) (print 1)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := scanner.NewScanner(tt.src)
+			s.Reporter = &diagnostics
+			p := NewParser(s.ScanTokens())
+			p.Reporter = &diagnostics
+			program := p.Parse()
+			if got := sstmtList(program.Statements); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+			if len(diagnostics) != 1 || diagnostics[0].Code[0] != 'S' {
+				t.Errorf("scanning and parsing reported %v, want only the scanner's diagnostic", diagnostics)
+			}
+			if !p.HadError {
+				t.Errorf("Parse() did not set HadError")
+			}
+		})
+	}
+}
+
+func TestParser_ParseExpression_tooManyArguments(t *testing.T) {
+	args := strings.Repeat("x, ", maxArguments) + "x"
+	p, got, diagnostics := parse("f(" + args + ")")
+	call, ok := got.(*ast.Call)
+	if !ok || len(call.Arguments) != maxArguments+1 {
+		t.Fatalf("ParseExpression() = %v, want a call with %d arguments", sexpr(got), maxArguments+1)
+	}
+	if !p.HadError || len(diagnostics) != 1 || diagnostics[0].Code != CodeTooManyArguments {
+		t.Errorf("ParseExpression() reported %v, want %s", diagnostics, CodeTooManyArguments)
+	}
+}
+
+func TestNewParser(t *testing.T) {
+	number := token.Token{Type: token.NUMBER, Lexeme: "1", Literal: int64(1), Line: 1, Span: token.Span{
+		Start: token.Position{Offset: 0, Line: 1, Column: 1},
+		End:   token.Position{Offset: 1, Line: 1, Column: 2},
+	}}
+	p := NewParser([]token.Token{number})
+	want := token.Token{Type: token.EOF, Lexeme: string('\000'), Line: 1, Span: token.Span{Start: number.Span.End, End: number.Span.End}}
+	if got := p.tokens[len(p.tokens)-1]; !reflect.DeepEqual(got, want) {
+		t.Errorf("NewParser() ended the tokens with %v, want %v", got, want)
+	}
+	if got := sexpr(p.ParseExpression()); got != "1" {
+		t.Errorf("ParseExpression() = %v, want 1", got)
+	}
+}
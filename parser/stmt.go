@@ -0,0 +1,180 @@
+package parser
+
+import (
+	"xolog/ast"
+	"xolog/token"
+)
+
+// declaration → classDecl | funDecl | varDecl | statement
+//
+// A declaration with a syntax error is replaced by a BadStmt after synchronizing.
+func (p *Parser) declaration() (stmt ast.Stmt) {
+	start := p.current
+	defer func() {
+		if r := recover(); r != nil {
+			if _, ok := r.(bailout); !ok {
+				panic(r)
+			}
+			p.synchronize(start)
+			stmt = &ast.BadStmt{From: p.tokens[start].Span.Start, To: p.endSince(start)}
+		}
+	}()
+	doc := p.docComment()
+	switch {
+	case p.match(token.CLASS):
+		return p.classDeclaration(doc)
+	case p.match(token.FUN):
+		return p.function(doc, p.previous, "function")
+	case p.match(token.VAR):
+		return p.varDeclaration(doc)
+	}
+	return p.statement()
+}
+
+// docComment will return the doc comments on the lines directly above the next token.
+// Comments separated from it by a blank line, or ending a line of code, are not included.
+func (p *Parser) docComment() []token.Token {
+	line := p.peek().Span.Start.Line
+	i := len(p.doc)
+	for i > 0 && p.doc[i-1].Span.End.Line == line-1 && p.doc[i-1].Span.Start.Line != p.previous.Span.End.Line {
+		i--
+		line = p.doc[i].Span.Start.Line
+	}
+	if i == len(p.doc) {
+		return nil
+	}
+	return p.doc[i:]
+}
+
+// classDecl → "class" IDENTIFIER ( "<" IDENTIFIER )? "{" function* "}"
+func (p *Parser) classDeclaration(doc []token.Token) ast.Stmt {
+	class := &ast.ClassStmt{Doc: doc, Keyword: p.previous}
+	class.Name = p.consume(token.IDENTIFIER, "class name")
+	if p.match(token.LESS) {
+		class.Superclass = &ast.Variable{Name: p.consume(token.IDENTIFIER, "superclass name")}
+	}
+	p.consume(token.LEFT_BRACE, "'{' before class body")
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		class.Methods = append(class.Methods, p.function(p.docComment(), token.Token{}, "method"))
+	}
+	class.Rbrace = p.consume(token.RIGHT_BRACE, "'}' after class body")
+	return class
+}
+
+// function → IDENTIFIER "(" parameters? ")" block
+//
+// keyword is the fun keyword of a function declaration, or the zero Token for a method.
+func (p *Parser) function(doc []token.Token, keyword token.Token, kind string) *ast.FunStmt {
+	fun := &ast.FunStmt{Doc: doc, Keyword: keyword}
+	fun.Name = p.consume(token.IDENTIFIER, kind+" name")
+	p.consume(token.LEFT_PAREN, "'(' after "+kind+" name")
+	if !p.check(token.RIGHT_PAREN) {
+		for {
+			if len(fun.Params) == maxArguments {
+				p.errorAt(p.peek(), CodeTooManyParameters, "Can't have more than %d parameters.", maxArguments)
+			}
+			fun.Params = append(fun.Params, p.consume(token.IDENTIFIER, "parameter name"))
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+	p.consume(token.RIGHT_PAREN, "')' after parameters")
+	p.consume(token.LEFT_BRACE, "'{' before "+kind+" body")
+	fun.Body = p.block()
+	return fun
+}
+
+// varDecl → "var" IDENTIFIER ( "=" expression )? ";"
+func (p *Parser) varDeclaration(doc []token.Token) ast.Stmt {
+	decl := &ast.VarStmt{Doc: doc, Keyword: p.previous}
+	decl.Name = p.consume(token.IDENTIFIER, "variable name")
+	if p.match(token.EQUAL) {
+		decl.Initializer = p.expression()
+	}
+	decl.Semicolon = p.consume(token.SEMICOLON, "';' after variable declaration")
+	return decl
+}
+
+// statement → exprStmt | forStmt | ifStmt | printStmt | returnStmt | whileStmt | block
+func (p *Parser) statement() ast.Stmt {
+	switch {
+	case p.match(token.FOR):
+		return p.forStatement()
+	case p.match(token.IF):
+		return p.ifStatement()
+	case p.match(token.PRINT):
+		keyword := p.previous
+		value := p.expression()
+		return &ast.PrintStmt{Keyword: keyword, Expression: value, Semicolon: p.consume(token.SEMICOLON, "';' after value")}
+	case p.match(token.RETURN):
+		return p.returnStatement()
+	case p.match(token.WHILE):
+		keyword := p.previous
+		p.consume(token.LEFT_PAREN, "'(' after 'while'")
+		condition := p.expression()
+		p.consume(token.RIGHT_PAREN, "')' after condition")
+		return &ast.WhileStmt{Keyword: keyword, Condition: condition, Body: p.statement()}
+	case p.match(token.LEFT_BRACE):
+		return p.block()
+	}
+	expr := p.expression()
+	return &ast.ExpressionStmt{Expression: expr, Semicolon: p.consume(token.SEMICOLON, "';' after expression")}
+}
+
+// forStmt → "for" "(" ( varDecl | exprStmt | ";" ) expression? ";" expression? ")" statement
+func (p *Parser) forStatement() ast.Stmt {
+	loop := &ast.ForStmt{Keyword: p.previous}
+	p.consume(token.LEFT_PAREN, "'(' after 'for'")
+	switch {
+	case p.match(token.SEMICOLON):
+	case p.match(token.VAR):
+		loop.Initializer = p.varDeclaration(nil)
+	default:
+		expr := p.expression()
+		loop.Initializer = &ast.ExpressionStmt{Expression: expr, Semicolon: p.consume(token.SEMICOLON, "';' after loop initializer")}
+	}
+	if !p.check(token.SEMICOLON) {
+		loop.Condition = p.expression()
+	}
+	p.consume(token.SEMICOLON, "';' after loop condition")
+	if !p.check(token.RIGHT_PAREN) {
+		loop.Increment = p.expression()
+	}
+	p.consume(token.RIGHT_PAREN, "')' after for clauses")
+	loop.Body = p.statement()
+	return loop
+}
+
+// ifStmt → "if" "(" expression ")" statement ( "else" statement )?
+func (p *Parser) ifStatement() ast.Stmt {
+	stmt := &ast.IfStmt{Keyword: p.previous}
+	p.consume(token.LEFT_PAREN, "'(' after 'if'")
+	stmt.Condition = p.expression()
+	p.consume(token.RIGHT_PAREN, "')' after if condition")
+	stmt.Then = p.statement()
+	if p.match(token.ELSE) {
+		stmt.Else = p.statement()
+	}
+	return stmt
+}
+
+// returnStmt → "return" expression? ";"
+func (p *Parser) returnStatement() ast.Stmt {
+	stmt := &ast.ReturnStmt{Keyword: p.previous}
+	if !p.check(token.SEMICOLON) {
+		stmt.Value = p.expression()
+	}
+	stmt.Semicolon = p.consume(token.SEMICOLON, "';' after return value")
+	return stmt
+}
+
+// block → "{" declaration* "}"
+func (p *Parser) block() *ast.BlockStmt {
+	block := &ast.BlockStmt{Lbrace: p.previous}
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		block.Statements = append(block.Statements, p.declaration())
+	}
+	block.Rbrace = p.consume(token.RIGHT_BRACE, "'}' after block")
+	return block
+}
@@ -0,0 +1,271 @@
+package parser
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"xolog/ast"
+	"xolog/error"
+	"xolog/scanner"
+	"xolog/token"
+)
+
+// parseProgram will scan and parse src as a program, and return the parser's diagnostics.
+func parseProgram(src string) (*Parser, *ast.Program, error.List) {
+	s := scanner.NewScanner(src)
+	s.Reporter = error.ReporterFunc(func(error.Diagnostic) {})
+	diagnostics := error.List{}
+	p := NewParser(s.ScanTokens())
+	p.Reporter = &diagnostics
+	return p, p.Parse(), diagnostics
+}
+
+// sstmt will write s as a parenthesized prefix form, using sexpr for expressions.
+func sstmt(s ast.Stmt) string {
+	optional := func(e ast.Expr) string {
+		if e == nil {
+			return "_"
+		}
+		return sexpr(e)
+	}
+	switch s := s.(type) {
+	case nil:
+		return "_"
+	case *ast.BadStmt:
+		return "(bad)"
+	case *ast.BlockStmt:
+		return fmt.Sprintf("(block %s)", sstmtList(s.Statements))
+	case *ast.ClassStmt:
+		parts := []string{"class", s.Name.Lexeme}
+		if s.Superclass != nil {
+			parts = append(parts, "<", s.Superclass.Name.Lexeme)
+		}
+		for _, m := range s.Methods {
+			parts = append(parts, sstmt(m))
+		}
+		return fmt.Sprintf("(%s)", strings.Join(parts, " "))
+	case *ast.ExpressionStmt:
+		return fmt.Sprintf("(; %s)", sexpr(s.Expression))
+	case *ast.ForStmt:
+		return fmt.Sprintf("(for %s %s %s %s)", sstmt(s.Initializer), optional(s.Condition), optional(s.Increment), sstmt(s.Body))
+	case *ast.FunStmt:
+		var params []string
+		for _, param := range s.Params {
+			params = append(params, param.Lexeme)
+		}
+		return fmt.Sprintf("(fun %s (%s) %s)", s.Name.Lexeme, strings.Join(params, " "), sstmt(s.Body))
+	case *ast.IfStmt:
+		return fmt.Sprintf("(if %s %s %s)", sexpr(s.Condition), sstmt(s.Then), sstmt(s.Else))
+	case *ast.PrintStmt:
+		return fmt.Sprintf("(print %s)", sexpr(s.Expression))
+	case *ast.ReturnStmt:
+		return fmt.Sprintf("(return %s)", optional(s.Value))
+	case *ast.VarStmt:
+		return fmt.Sprintf("(var %s %s)", s.Name.Lexeme, optional(s.Initializer))
+	case *ast.WhileStmt:
+		return fmt.Sprintf("(while %s %s)", sexpr(s.Condition), sstmt(s.Body))
+	}
+	return fmt.Sprintf("%T", s)
+}
+
+func TestParser_Parse(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want string
+	}{
+		{name: "Parse an empty program.", src: "", want: ""},
+		{name: "Parse variable declarations.", src: "var a; var b = a + 1;", want: "(var a _) (var b (+ a 1))"},
+		{name: "Parse print and expression statements.", src: "print 1; a = 2;", want: "(print 1) (; (= a 2))"},
+		{name: "Parse nested blocks.", src: "{ var a = 1; { print a; } }", want: "(block (var a 1) (block (print a)))"},
+		{name: "Parse if with a dangling else.", src: "if (a) if (b) print 1; else print 2;", want: "(if a (if b (print 1) (print 2)) _)"},
+		{name: "Parse while.", src: "while (i < 3) i = i + 1;", want: "(while (< i 3) (; (= i (+ i 1))))"},
+		{name: "Parse for with every clause.", src: "for (var i = 0; i < 3; i = i + 1) print i;", want: "(for (var i 0) (< i 3) (= i (+ i 1)) (print i))"},
+		{name: "Parse for with no clauses.", src: "for (;;) {}", want: "(for _ _ _ (block ))"},
+		{name: "Parse for with an expression initializer.", src: "for (i = 0; ; ) print i;", want: "(for (; (= i 0)) _ _ (print i))"},
+		{name: "Parse functions and returns.", src: "fun f(a, b) { return a; } fun g() { return; }", want: "(fun f (a b) (block (return a))) (fun g () (block (return _)))"},
+		{name: "Parse classes.", src: "class A < B { init(x) { this.x = x; } get() { return super.get(); } }", want: "(class A < B (fun init (x) (block (; (= (. this x) x)))) (fun get () (block (return (call (super get))))))"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, program, diagnostics := parseProgram(tt.src)
+			if got := sstmtList(program.Statements); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+			if p.HadError || len(diagnostics) != 0 {
+				t.Errorf("Parse() reported %v", diagnostics)
+			}
+			if program.EOF.Type != token.EOF {
+				t.Errorf("Parse() ended the program with %v, want EOF", program.EOF)
+			}
+		})
+	}
+}
+
+// lexemes will return the lexemes of tokens.
+func lexemes(tokens []token.Token) []string {
+	var l []string
+	for _, tok := range tokens {
+		l = append(l, tok.Lexeme)
+	}
+	return l
+}
+
+// sstmtList will write stmts with sstmt, separated by spaces.
+func sstmtList(stmts []ast.Stmt) string {
+	var parts []string
+	for _, s := range stmts {
+		parts = append(parts, sstmt(s))
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestParser_Parse_doc(t *testing.T) {
+	src := strings.Join([]string{
+		"/// Detached.",
+		"",
+		"/// A counter.",
+		"/// Counts up.",
+		"class Counter {",
+		"  /// Adds one.",
+		"  add() {}",
+		"  sub() {} /// Not the doc of next.",
+		"  next() {}",
+		"}",
+		"var a; /// Not the doc of b.",
+		"var b;",
+		"/// Greets.",
+		"fun hi() {}",
+	}, "\n")
+	_, program, diagnostics := parseProgram(src)
+	if len(diagnostics) != 0 {
+		t.Fatalf("Parse() reported %v", diagnostics)
+	}
+	class := program.Statements[0].(*ast.ClassStmt)
+	tests := []struct {
+		name string
+		doc  []string
+		want []string
+	}{
+		{name: "class", doc: lexemes(class.Doc), want: []string{"/// A counter.", "/// Counts up."}},
+		{name: "add", doc: lexemes(class.Methods[0].Doc), want: []string{"/// Adds one."}},
+		{name: "sub", doc: lexemes(class.Methods[1].Doc), want: nil},
+		{name: "next", doc: lexemes(class.Methods[2].Doc), want: nil},
+		{name: "a", doc: lexemes(program.Statements[1].(*ast.VarStmt).Doc), want: nil},
+		{name: "b", doc: lexemes(program.Statements[2].(*ast.VarStmt).Doc), want: nil},
+		{name: "hi", doc: lexemes(program.Statements[3].(*ast.FunStmt).Doc), want: []string{"/// Greets."}},
+	}
+	for _, tt := range tests {
+		if strings.Join(tt.doc, "|") != strings.Join(tt.want, "|") {
+			t.Errorf("Doc of %s = %q, want %q", tt.name, tt.doc, tt.want)
+		}
+	}
+}
+
+func TestParser_Parse_recovery(t *testing.T) {
+	type diagnostic struct {
+		code string
+		line int
+	}
+	tests := []struct {
+		name string
+		src  string
+		want string
+		diag []diagnostic
+	}{
+		{
+			name: "Replace a missing operand and keep the statement.",
+			src:  "var a = 1;\nprint ;\nprint a;",
+			want: "(var a 1) (print (bad)) (print a)",
+			diag: []diagnostic{{CodeExpectedExpression, 2}},
+		},
+		{
+			name: "Skip to the semicolon after an error.",
+			src:  "var = 1 2 3;\nprint 1;",
+			want: "(bad) (print 1)",
+			diag: []diagnostic{{CodeExpectedToken, 1}},
+		},
+		{
+			name: "Skip to the next statement keyword.",
+			src:  "print 1\nwhile (x) print 2;\nfun (a) {}\nclass A { 1 }\nvar b;",
+			want: "(bad) (while x (print 2)) (bad) (bad) (var b _)",
+			diag: []diagnostic{{CodeExpectedToken, 2}, {CodeExpectedToken, 3}, {CodeExpectedToken, 4}},
+		},
+		{
+			name: "Recover within a block.",
+			src:  "{ print 1 + ; var a a; print 2; }",
+			want: "(block (print (+ 1 (bad))) (bad) (print 2))",
+			diag: []diagnostic{{CodeExpectedExpression, 1}, {CodeExpectedToken, 1}},
+		},
+		{
+			name: "Report every error, without repeating one at the same token.",
+			src:  "a + b = c;\n(1;\nf(;",
+			want: "(; (+ a b)) (bad) (bad)",
+			diag: []diagnostic{{CodeInvalidAssignment, 1}, {CodeExpectedToken, 2}, {CodeExpectedExpression, 3}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, program, diagnostics := parseProgram(tt.src)
+			if got := sstmtList(program.Statements); got != tt.want {
+				t.Errorf("Parse() = %v, want %v", got, tt.want)
+			}
+			var got []diagnostic
+			for _, d := range diagnostics {
+				got = append(got, diagnostic{d.Code, d.Span.Start.Line})
+			}
+			if !reflect.DeepEqual(got, tt.diag) {
+				t.Errorf("Parse() reported %v, want %v", diagnostics, tt.diag)
+			}
+			if !p.HadError {
+				t.Errorf("Parse() did not set HadError")
+			}
+			if program.EOF.Type != token.EOF {
+				t.Errorf("Parse() ended the program with %v, want EOF", program.EOF)
+			}
+		})
+	}
+}
+
+func TestParser_Parse_badStmtSpan(t *testing.T) {
+	_, program, _ := parseProgram("print 1;\nvar = 2;\nprint 3;")
+	bad, ok := program.Statements[1].(*ast.BadStmt)
+	if !ok {
+		t.Fatalf("Parse() = %v, want a BadStmt second", sstmtList(program.Statements))
+	}
+	if bad.From.Offset != 9 || bad.To.Offset != 17 {
+		t.Errorf("BadStmt spans %d-%d, want 9-17", bad.From.Offset, bad.To.Offset)
+	}
+}
+
+func TestParser_MaxErrors(t *testing.T) {
+	s := scanner.NewScanner("print ; print ; print ;")
+	diagnostics := error.List{}
+	p := NewParser(s.ScanTokens())
+	p.Reporter = &diagnostics
+	p.MaxErrors = 2
+	p.Parse()
+	var codes []string
+	for _, d := range diagnostics {
+		codes = append(codes, d.Code)
+	}
+	want := []string{CodeExpectedExpression, CodeExpectedExpression, error.CodeTooManyErrors}
+	if !reflect.DeepEqual(codes, want) || diagnostics[2].Severity != error.SeverityNote {
+		t.Errorf("Parse() reported %v, want codes %v", diagnostics, want)
+	}
+	if !p.HadError {
+		t.Errorf("Parse() did not set HadError")
+	}
+}
+
+func TestParseProgram(t *testing.T) {
+	s := scanner.NewScanner("print 1 print 2;")
+	program, diagnostics := ParseProgram(s.ScanTokens())
+	if got, want := sstmtList(program.Statements), "(bad) (print 2)"; got != want {
+		t.Errorf("ParseProgram() = %v, want %v", got, want)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeExpectedToken {
+		t.Errorf("ParseProgram() reported %v, want %s", diagnostics, CodeExpectedToken)
+	}
+}
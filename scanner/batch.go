@@ -0,0 +1,77 @@
+package scanner
+
+import (
+	"bufio"
+	"os"
+	"runtime"
+	"xolog/error"
+	"xolog/token"
+)
+
+// Result holds the tokens and diagnostics of one scanned file. Files that cannot be read
+// have a single CodeReadError diagnostic and no tokens.
+type Result struct {
+	Path        string
+	Tokens      []token.Token
+	Diagnostics error.List
+}
+
+// ScanFiles scans the files at paths with at most workers goroutines, or one per CPU when
+// workers is not positive, and calls emit with each result in the order of paths. Each
+// file's diagnostics are sorted, so output does not depend on scheduling. configure, when
+// not nil, may set options such as Mode on each Scanner; its Reporter is replaced.
+func ScanFiles(paths []string, workers int, configure func(*Scanner), emit func(Result)) {
+	if workers < 1 {
+		workers = runtime.NumCPU()
+	}
+	results := make([]chan Result, len(paths))
+	for i := range results {
+		results[i] = make(chan Result, 1)
+	}
+	// Files may finish out of order; slots bound how many results wait for emit.
+	slots := make(chan struct{}, 2*workers)
+	jobs := make(chan int)
+	go func() {
+		for i := range paths {
+			slots <- struct{}{}
+			jobs <- i
+		}
+		close(jobs)
+	}()
+	for w := 0; w < workers; w++ {
+		go func() {
+			for i := range jobs {
+				results[i] <- scanPath(paths[i], configure)
+			}
+		}()
+	}
+	for i := range paths {
+		emit(<-results[i])
+		<-slots
+	}
+}
+
+// scanPath will scan the file at path into a Result.
+func scanPath(path string, configure func(*Scanner)) Result {
+	result := Result{Path: path}
+	file, err := os.Open(path)
+	if err != nil {
+		result.Diagnostics.Report(error.Diagnostic{
+			Severity: error.SeverityError,
+			Span:     token.Span{Start: token.Position{Filename: path}},
+			Code:     CodeReadError,
+			Message:  err.Error(),
+		})
+		return result
+	}
+	defer file.Close()
+
+	s := NewReaderScanner(path, bufio.NewReader(file))
+	if configure != nil {
+		configure(s)
+	}
+	s.Reporter = &result.Diagnostics
+	result.Tokens = s.ScanTokens()
+	result.Diagnostics.Sort()
+	return result
+}
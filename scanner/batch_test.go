@@ -0,0 +1,98 @@
+package scanner
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"xolog/error"
+)
+
+// writeTree creates files under a temporary directory, and returns the directory.
+func writeTree(t *testing.T, files map[string]string) string {
+	dir, err := ioutil.TempDir("", "xolog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, src := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := ioutil.WriteFile(path, []byte(src), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestExpandPaths(t *testing.T) {
+	dir := writeTree(t, map[string]string{
+		"b.xo":          "",
+		"a.xo":          "",
+		"notes.txt":     "",
+		"lib/c.xo":      "",
+		"lib/deep/d.xo": "",
+	})
+	defer os.RemoveAll(dir)
+
+	got, err := ExpandPaths([]string{filepath.Join(dir, "lib") + "/...", "other.xo", dir + "/...", "./other.xo"})
+	if err != nil {
+		t.Fatalf("ExpandPaths() error = %v", err)
+	}
+	want := []string{
+		filepath.Join(dir, "lib", "c.xo"),
+		filepath.Join(dir, "lib", "deep", "d.xo"),
+		"other.xo",
+		filepath.Join(dir, "a.xo"),
+		filepath.Join(dir, "b.xo"),
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("ExpandPaths() = %v, want %v", got, want)
+	}
+	if _, err := ExpandPaths([]string{filepath.Join(dir, "missing") + "/..."}); err == nil {
+		t.Errorf("ExpandPaths() of a missing directory returned no error")
+	}
+}
+
+func TestScanFiles(t *testing.T) {
+	files := map[string]string{}
+	for i := 0; i < 40; i++ {
+		files[string(rune('a'+i%26))+strings.Repeat("x", i/26)+".xo"] = strings.Repeat("var a = 1; @\n", i)
+	}
+	dir := writeTree(t, files)
+	defer os.RemoveAll(dir)
+	paths, err := ExpandPaths([]string{dir + "/..."})
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths = append(paths, filepath.Join(dir, "missing.xo"))
+
+	var got []Result
+	ScanFiles(paths, 4, func(s *Scanner) { s.Mode = ScanTrivia }, func(r Result) {
+		got = append(got, r)
+	})
+	if len(got) != len(paths) {
+		t.Fatalf("ScanFiles() emitted %d results, want %d", len(got), len(paths))
+	}
+	for i, r := range got[:len(got)-1] {
+		if r.Path != paths[i] {
+			t.Errorf("result %d path = %v, want %v", i, r.Path, paths[i])
+		}
+		src, _ := ioutil.ReadFile(paths[i])
+		diagnostics := error.List{}
+		s := NewScanner(string(src))
+		s.Mode = ScanTrivia
+		s.Reporter = &diagnostics
+		want := s.ScanTokens()
+		if len(r.Tokens) != len(want) || len(r.Diagnostics) != len(diagnostics) {
+			t.Errorf("result %d = %d tokens, %d diagnostics, want %d, %d", i, len(r.Tokens), len(r.Diagnostics), len(want), len(diagnostics))
+		}
+	}
+	missing := got[len(got)-1]
+	if len(missing.Tokens) != 0 || len(missing.Diagnostics) != 1 || missing.Diagnostics[0].Code != CodeReadError {
+		t.Errorf("missing file result = %v, want one read error", missing)
+	}
+}
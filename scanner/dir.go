@@ -0,0 +1,45 @@
+package scanner
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// FileExt is the extension of Xolog source files.
+const FileExt = ".xo"
+
+// ExpandPaths resolves script arguments into file paths. An argument ending in "/..." names
+// every FileExt file in that directory and below it, in lexical order; other arguments are
+// used as they are. A file named more than once is only kept where it first appears.
+func ExpandPaths(args []string) ([]string, error) {
+	var paths []string
+	seen := map[string]bool{}
+	add := func(path string) {
+		if key := filepath.Clean(path); !seen[key] {
+			seen[key] = true
+			paths = append(paths, path)
+		}
+	}
+	for _, arg := range args {
+		recursive := arg == "..." || strings.HasSuffix(arg, "/...") || strings.HasSuffix(arg, string(filepath.Separator)+"...")
+		if !recursive {
+			add(arg)
+			continue
+		}
+		root := filepath.Clean(strings.TrimSuffix(arg, "..."))
+		err := filepath.Walk(root, func(path string, info os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+			if !info.IsDir() && filepath.Ext(path) == FileExt {
+				add(path)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return paths, nil
+}
@@ -0,0 +1,141 @@
+package scanner
+
+import (
+	"sort"
+	"xolog/token"
+)
+
+// Edit replaces the bytes [Start, End) of a source with Text.
+type Edit struct {
+	Start int
+	End   int
+	Text  string
+}
+
+// Rescan accepts the tokens of a previous version of the source and the edit that produced
+// the current one, and returns the tokens of the current source. Scanning restarts at the
+// last line break before the edit and stops as soon as a token lines up with an old one past
+// it; the remaining old tokens are shifted into place. Only diagnostics in the rescanned
+// region are reported. The Scanner must hold the whole edited source, as NewScanner and
+// NewFileScanner do, and be configured with the Mode the old tokens were scanned with.
+func (s *Scanner) Rescan(old []token.Token, edit Edit) []token.Token {
+	delta := len(edit.Text) - (edit.End - edit.Start)
+
+	restart := restartIndex(old, edit, s.Mode&ScanTrivia != 0)
+	if restart > 0 {
+		from := old[restart].Span.Start
+		s.current = from.Offset
+		s.line = from.Line
+		s.column = from.Column - 1
+		s.trivia = append([]token.Trivia(nil), old[restart].LeadingTrivia...)
+		s.tokens = append(s.tokens, old[:restart]...)
+		// Trivia is only ever added to the last token, so copying its trailing trivia is
+		// enough to keep the caller's tokens unchanged.
+		last := &s.tokens[len(s.tokens)-1]
+		last.TrailingTrivia = append([]token.Trivia(nil), last.TrailingTrivia...)
+		s.recordLines(0, s.current)
+	}
+
+	for !s.isAtEnd() {
+		n := len(s.tokens)
+		s.scan()
+		if len(s.tokens) == n {
+			continue
+		}
+		next := s.tokens[n]
+		if next.Span.Start.Offset < edit.Start+len(edit.Text) {
+			continue
+		}
+		if j, ok := resyncIndex(old, next, delta); ok {
+			s.tokens = s.tokens[:n]
+			s.trailing = false
+			s.recordLines(next.Span.Start.Offset, len(s.source))
+			return s.shift(old[j:], next)
+		}
+	}
+	s.addEOF()
+	return s.tokens
+}
+
+// restartIndex will return the index of the first old token that lies before the edit and
+// starts a line after its predecessor, so that nothing scanned before it can look into the
+// edit. With trivia, the predecessor's trailing trivia must also be closed by a newline,
+// since until then it collects whatever follows. Zero means scanning restarts from the
+// beginning.
+func restartIndex(old []token.Token, edit Edit, trivia bool) int {
+	i := sort.Search(len(old), func(i int) bool { return old[i].Span.Start.Offset >= edit.Start }) - 1
+	for ; i > 0; i-- {
+		if old[i].Type == token.EOF || old[i-1].Span.End.Line >= old[i].Span.Start.Line {
+			continue
+		}
+		if trailing := old[i-1].TrailingTrivia; trivia && (len(trailing) == 0 || trailing[len(trailing)-1].Kind != token.NEWLINE) {
+			continue
+		}
+		return i
+	}
+	return 0
+}
+
+// resyncIndex will return the index of the old token that next replaces, when next starts
+// where that token started before the edit and was scanned identically.
+func resyncIndex(old []token.Token, next token.Token, delta int) (int, bool) {
+	offset := next.Span.Start.Offset - delta
+	j := sort.Search(len(old), func(i int) bool { return old[i].Span.Start.Offset >= offset })
+	if j == len(old) || old[j].Span.Start.Offset != offset || old[j].Type == token.EOF {
+		return 0, false
+	}
+	o := old[j]
+	if o.Type != next.Type || o.Lexeme != next.Lexeme || !sameTrivia(o.LeadingTrivia, next.LeadingTrivia) {
+		return 0, false
+	}
+	return j, true
+}
+
+// sameTrivia will return whether a and b hold the same trivia.
+func sameTrivia(a, b []token.Trivia) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// shift will append old to the scanned tokens, moving them to where their first token now
+// starts according to next. Columns only move on the line that token starts on.
+func (s *Scanner) shift(old []token.Token, next token.Token) []token.Token {
+	from, to := old[0].Span.Start, next.Span.Start
+	offsets, lines, columns := to.Offset-from.Offset, to.Line-from.Line, to.Column-from.Column
+	move := func(p token.Position) token.Position {
+		if p.Line == from.Line {
+			p.Column += columns
+		}
+		p.Filename = s.filename()
+		p.Offset += offsets
+		p.Line += lines
+		return p
+	}
+	for _, tok := range old {
+		tok.Span = token.Span{Start: move(tok.Span.Start), End: move(tok.Span.End)}
+		tok.Line += lines
+		tok.Pos = s.pos(tok.Span.Start.Offset)
+		s.tokens = append(s.tokens, tok)
+	}
+	return s.tokens
+}
+
+// recordLines will record the line starts in source[from:to] with the file, for text that
+// is not scanned again.
+func (s *Scanner) recordLines(from, to int) {
+	if s.file == nil {
+		return
+	}
+	for i := from; i < to; i++ {
+		if c := s.source[i]; c == '\n' || (c == '\r' && (i+1 == len(s.source) || s.source[i+1] != '\n')) {
+			s.file.AddLine(s.offset + i + 1)
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package scanner
+
+import (
+	"reflect"
+	"testing"
+	"xolog/error"
+	"xolog/token"
+)
+
+func TestScanner_Rescan(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		edit   Edit
+	}{
+		{name: "Insert into an identifier", source: "var ab = 1;\nprint ab;\n", edit: Edit{Start: 6, End: 6, Text: "c"}},
+		{name: "Delete a line", source: "var a = 1;\nvar b = 2;\nprint a + b;\n", edit: Edit{Start: 11, End: 22, Text: ""}},
+		{name: "Insert lines", source: "var a = 1;\nprint a;\n", edit: Edit{Start: 11, End: 11, Text: "a = a\n  + 2;\n"}},
+		{name: "Replace on the last line", source: "a\nb\nc", edit: Edit{Start: 4, End: 5, Text: "cd"}},
+		{name: "Open a string", source: "a\nb c\nd\n", edit: Edit{Start: 2, End: 2, Text: "'"}},
+		{name: "Open a block comment", source: "a;\nb;\nc;\n", edit: Edit{Start: 3, End: 3, Text: "/*"}},
+		{name: "Extend a number", source: "x\n1.a\n", edit: Edit{Start: 4, End: 5, Text: "5"}},
+		{name: "Insert at the start", source: "a b\nc\n", edit: Edit{Start: 0, End: 0, Text: "  z"}},
+		{name: "Append at the end", source: "a b\nc", edit: Edit{Start: 5, End: 5, Text: " // d"}},
+		{name: "Indent a line", source: "a\n  b // c\n  d\n", edit: Edit{Start: 2, End: 2, Text: " "}},
+		{name: "Shift a multi-line string", source: "a\nb 'c\nd' e\n", edit: Edit{Start: 2, End: 3, Text: "bb"}},
+		{name: "Edit after a multi-line trailing comment", source: "*var1\u00e9\r2/*\r\n*//a{/@#!'", edit: Edit{Start: 16, End: 16, Text: "*a//"}},
+	}
+	for _, tt := range tests {
+		edited := tt.source[:tt.edit.Start] + tt.edit.Text + tt.source[tt.edit.End:]
+		for _, mode := range []Mode{0, ScanTrivia} {
+			t.Run(tt.name, func(t *testing.T) {
+				scan := func(src string) (*Scanner, *token.File) {
+					file := token.NewFileSet().AddFile("test.xlg", []byte(src))
+					s := NewFileScanner(file)
+					s.Reporter = &error.List{}
+					s.Mode = mode
+					return s, file
+				}
+				before, _ := scan(tt.source)
+				old := before.ScanTokens()
+				again, _ := scan(tt.source)
+				pristine := again.ScanTokens()
+				full, fullFile := scan(edited)
+				want := full.ScanTokens()
+
+				s, file := scan(edited)
+				got := s.Rescan(old, tt.edit)
+				if !reflect.DeepEqual(got, want) {
+					t.Errorf("Scanner.Rescan() = %v, want %v", got, want)
+				}
+				if !reflect.DeepEqual(old, pristine) {
+					t.Errorf("Scanner.Rescan() modified the old tokens to %v, want %v", old, pristine)
+				}
+				if file.LineCount() != fullFile.LineCount() {
+					t.Errorf("File.LineCount() = %v, want %v", file.LineCount(), fullFile.LineCount())
+				}
+				if mode == ScanTrivia && token.Reconstruct(got) != edited {
+					t.Errorf("token.Reconstruct(Scanner.Rescan()) = %q, want %q", token.Reconstruct(got), edited)
+				}
+			})
+		}
+	}
+}
+
+func TestScanner_Rescan_reportsEditedRegion(t *testing.T) {
+	source := "var a = 1;\nvar b = 2;\n@\n"
+	old := NewScanner(source)
+	old.Reporter = &error.List{}
+	tokens := old.ScanTokens()
+
+	edit := Edit{Start: 8, End: 9, Text: "#"}
+	s := NewScanner(source[:edit.Start] + edit.Text + source[edit.End:])
+	diagnostics := error.List{}
+	s.Reporter = &diagnostics
+	s.Rescan(tokens, edit)
+	if len(diagnostics) != 1 || diagnostics[0].Span.Start.Offset != 8 {
+		t.Errorf("Scanner.Rescan() reported %v, want only the error at offset 8", diagnostics)
+	}
+}
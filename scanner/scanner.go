@@ -1,19 +1,102 @@
 package scanner
 
 import (
+	"fmt"
+	"io"
+	"math"
+	"strconv"
+	"strings"
 	"unicode"
 	"unicode/utf8"
 	"xolog/error"
 	"xolog/token"
 )
 
+// keywords maps reserved words to their token types.
+var keywords = map[string]token.TokenType{
+	"and":    token.AND,
+	"class":  token.CLASS,
+	"else":   token.ELSE,
+	"false":  token.FALSE,
+	"for":    token.FOR,
+	"fun":    token.FUN,
+	"if":     token.IF,
+	"nil":    token.NIL,
+	"or":     token.OR,
+	"print":  token.PRINT,
+	"return": token.RETURN,
+	"super":  token.SUPER,
+	"this":   token.THIS,
+	"true":   token.TRUE,
+	"var":    token.VAR,
+	"while":  token.WHILE,
+}
+
+// keywordLiterals holds the literal values of keywords that denote one.
+var keywordLiterals = map[token.TokenType]interface{}{
+	token.TRUE:  true,
+	token.FALSE: false,
+}
+
+// Diagnostic codes reported by the Scanner. S0008 is reserved: it was the note on too many
+// errors, which error.Limit now reports as error.CodeTooManyErrors.
+const (
+	CodeUnexpectedCharacter = "S0001"
+	CodeUnterminatedString  = "S0002"
+	CodeReadError           = "S0003"
+	CodeInvalidEscape       = "S0004"
+	CodeMalformedNumber     = "S0005"
+	CodeNumberRange         = "S0006"
+	CodeUnterminatedComment = "S0007"
+	CodeInvalidUTF8         = "S0009"
+	CodeNumberPrecision     = "S0010"
+)
+
+// tokenStarts holds the characters other than digits and letters that begin a token or trivia.
+const tokenStarts = "(){},.-+;*%?:&|^~!=<>/\"'` \t\r\n"
+
+// smallestNormal is the smallest positive float64 with full precision.
+const smallestNormal = 0x1p-1022
+
+// baseNames names the bases number literals may be written in.
+var baseNames = map[int]string{2: "binary", 8: "octal", 10: "decimal", 16: "hexadecimal"}
+
+// Mode controls optional scanner behaviour.
+type Mode uint
+
+const (
+	// ScanTrivia attaches whitespace and comments to tokens as trivia, so that token.Reconstruct reproduces the source byte for byte.
+	ScanTrivia Mode = 1 << iota
+)
+
+// chunkSize is the number of bytes requested from a reader at a time.
+const chunkSize = 4096
+
 type Scanner struct {
-	source   []byte
-	start    int
-	current  int
-	line     int
-	tokens   []token.Token
-	HadError bool
+	file        *token.File
+	name        string
+	reader      io.Reader
+	offset      int // absolute offset of source[0]; non-zero once a streamed buffer is discarded
+	source      []byte
+	start       int
+	current     int
+	line        int
+	column      int
+	startLine   int
+	startColumn int
+	tokens      []token.Token
+	errors      int
+	trivia      []token.Trivia // leading trivia for the next token
+	trailing    bool           // whether trivia still trails the last token
+	limit       error.Reporter // Reporter wrapped by error.Limit, once MaxErrors is in force
+	HadError    bool
+	// Reporter receives diagnostics as they are found; nil reports to error.Default.
+	Reporter error.Reporter
+	// MaxErrors caps the number of errors passed to the Reporter; zero means no limit.
+	// Scanning carries on past the cap, and HadError is still set.
+	MaxErrors int
+	// Mode selects optional behaviour; it must be set before scanning starts.
+	Mode Mode
 }
 
 // NewScanner accepts a string, and returns a pointer to the initialized Scanner struct.
@@ -21,18 +104,167 @@ func NewScanner(source string) *Scanner {
 	return &Scanner{source: []byte(source), start: 0, current: 0, line: 1, tokens: []token.Token{}}
 }
 
+// NewFileScanner accepts a file registered with a token.FileSet, and returns a pointer to
+// a Scanner over its source. Tokens carry the file name and Pos values within the set.
+func NewFileScanner(file *token.File) *Scanner {
+	return &Scanner{file: file, source: file.Source(), start: 0, current: 0, line: 1, tokens: []token.Token{}}
+}
+
+// NewReaderScanner accepts a reader and the name to record in token positions, and returns
+// a pointer to a Scanner that reads source lazily. Use Next to consume tokens with bounded memory.
+func NewReaderScanner(name string, r io.Reader) *Scanner {
+	return &Scanner{name: name, reader: r, source: make([]byte, 0, chunkSize), start: 0, current: 0, line: 1, tokens: []token.Token{}}
+}
+
 // ScanTokens will return an array of source length tokens of type Token.
 func (s *Scanner) ScanTokens() []token.Token {
-	for s.current < len(s.source) {
+	for !s.isAtEnd() {
+		s.scan()
+	}
+	s.addEOF()
+	return s.tokens
+}
+
+// Next will scan and return the next token. Once the source is exhausted every call returns
+// an EOF token. Next and ScanTokens should not be mixed on the same Scanner.
+func (s *Scanner) Next() token.Token {
+	for !s.ready() {
+		s.discard()
+		if s.isAtEnd() {
+			s.addEOF()
+			break
+		}
+		s.scan()
+	}
+	next := s.tokens[0]
+	s.tokens = s.tokens[:copy(s.tokens, s.tokens[1:])]
+	return next
+}
+
+// ready will return whether the first queued token is complete, including its trailing trivia.
+func (s *Scanner) ready() bool {
+	n := len(s.tokens)
+	return n > 1 || (n == 1 && !s.trailing)
+}
+
+// scan will scan the next token, or the next piece of trivia.
+func (s *Scanner) scan() {
+	if s.offset+s.current == 0 {
+		s.prologue()
+		if s.isAtEnd() {
+			return
+		}
+	}
+	s.start = s.current
+	n := len(s.tokens)
+	s.scanToken()
+	if s.Mode&ScanTrivia != 0 && len(s.tokens) == n {
+		text := string(s.source[s.start:s.current])
+		s.addTrivia(triviaKind(text), text)
+	}
+}
+
+// prologue will skip a token.BOM and a #! line at the start of the source, keeping them as
+// trivia when requested.
+func (s *Scanner) prologue() {
+	s.fill(len(token.BOM))
+	if strings.HasPrefix(string(s.source[s.current:]), token.BOM) {
+		s.current += len(token.BOM)
+		if s.Mode&ScanTrivia != 0 {
+			s.addTrivia(token.WHITESPACE, token.BOM)
+		}
+	}
+	s.fill(2)
+	if strings.HasPrefix(string(s.source[s.current:]), "#!") {
 		s.start = s.current
-		s.scanToken()
+		for s.peek() != '\n' && s.peek() != '\r' && !s.isAtEnd() {
+			s.advance()
+		}
+		if s.Mode&ScanTrivia != 0 {
+			s.addTrivia(token.COMMENT, string(s.source[s.start:s.current]))
+		}
 	}
-	eof := token.Token{Type: token.EOF, Lexeme: string('\000'), Literal: nil, Line: s.line}
+}
+
+// addTrivia will record text as trivia, trailing the last token until a newline is seen,
+// and leading the next token otherwise.
+func (s *Scanner) addTrivia(kind token.TriviaKind, text string) {
+	if s.trailing {
+		last := &s.tokens[len(s.tokens)-1]
+		last.TrailingTrivia = appendTrivia(last.TrailingTrivia, kind, text)
+		s.trailing = kind != token.NEWLINE
+		return
+	}
+	s.trivia = appendTrivia(s.trivia, kind, text)
+}
+
+// triviaKind will classify text that scanToken consumed without adding a token.
+func triviaKind(text string) token.TriviaKind {
+	switch {
+	case text == "\n" || text == "\r" || text == "\r\n":
+		return token.NEWLINE
+	case strings.Trim(text, " \t") == "":
+		return token.WHITESPACE
+	}
+	return token.COMMENT
+}
+
+// appendTrivia will append text to trivia, merging runs of whitespace.
+func appendTrivia(trivia []token.Trivia, kind token.TriviaKind, text string) []token.Trivia {
+	if n := len(trivia); n > 0 && trivia[n-1].Kind == kind && kind == token.WHITESPACE {
+		trivia[n-1].Text += text
+		return trivia
+	}
+	return append(trivia, token.Trivia{Kind: kind, Text: text})
+}
+
+// addEOF will add the EOF token at the current position, carrying any remaining trivia.
+func (s *Scanner) addEOF() {
+	pos := s.position()
+	eof := token.Token{Type: token.EOF, Lexeme: string('\000'), Literal: nil, Line: s.line, Span: token.Span{Start: pos, End: pos}, Pos: s.pos(pos.Offset), LeadingTrivia: s.trivia}
 	s.tokens = append(s.tokens, eof)
-	return s.tokens
+	s.trivia = nil
+	s.trailing = false
+}
+
+// discard will drop consumed bytes from a streamed buffer, so that memory is bounded by the longest token.
+func (s *Scanner) discard() {
+	if s.reader == nil || s.current == 0 {
+		return
+	}
+	n := copy(s.source, s.source[s.current:])
+	s.source = s.source[:n]
+	s.offset += s.current
+	s.start, s.current = 0, 0
+}
+
+// fill will read from the reader until n bytes past current are buffered, or the reader is exhausted.
+func (s *Scanner) fill(n int) {
+	for s.reader != nil && len(s.source)-s.current < n {
+		if cap(s.source)-len(s.source) < chunkSize {
+			grown := make([]byte, len(s.source), 2*cap(s.source)+chunkSize)
+			copy(grown, s.source)
+			s.source = grown
+		}
+		m, err := s.reader.Read(s.source[len(s.source):cap(s.source)])
+		s.source = s.source[:len(s.source)+m]
+		if err != nil {
+			if err != io.EOF {
+				pos := s.position()
+				s.report(error.Diagnostic{
+					Severity: error.SeverityError,
+					Span:     token.Span{Start: pos, End: pos},
+					Code:     CodeReadError,
+					Message:  fmt.Sprintf("Read error: %v", err),
+				})
+			}
+			s.reader = nil
+		}
+	}
 }
 
 func (s *Scanner) scanToken() {
+	s.startLine, s.startColumn = s.line, s.column
 	c := s.advance()
 	switch c {
 	case '(':
@@ -46,15 +278,65 @@ func (s *Scanner) scanToken() {
 	case ',':
 		s.addToken(token.COMMA, nil)
 	case '.':
-		s.addToken(token.DOT, nil)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.advance()
+			s.advance()
+			s.addToken(token.DOT_DOT_DOT, nil)
+		} else if s.match('.') {
+			s.addToken(token.DOT_DOT, nil)
+		} else {
+			s.addToken(token.DOT, nil)
+		}
 	case '-':
-		s.addToken(token.MINUS, nil)
+		if s.match('-') {
+			s.addToken(token.MINUS_MINUS, nil)
+		} else if s.match('=') {
+			s.addToken(token.MINUS_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(token.ARROW, nil)
+		} else {
+			s.addToken(token.MINUS, nil)
+		}
 	case '+':
-		s.addToken(token.PLUS, nil)
+		if s.match('+') {
+			s.addToken(token.PLUS_PLUS, nil)
+		} else if s.match('=') {
+			s.addToken(token.PLUS_EQUAL, nil)
+		} else {
+			s.addToken(token.PLUS, nil)
+		}
 	case ';':
 		s.addToken(token.SEMICOLON, nil)
 	case '*':
-		s.addToken(token.STAR, nil)
+		if s.match('*') {
+			s.addToken(token.STAR_STAR, nil)
+		} else if s.match('=') {
+			s.addToken(token.STAR_EQUAL, nil)
+		} else {
+			s.addToken(token.STAR, nil)
+		}
+	case '%':
+		if s.match('=') {
+			s.addToken(token.PERCENT_EQUAL, nil)
+		} else {
+			s.addToken(token.PERCENT, nil)
+		}
+	case '?':
+		s.addToken(token.QUESTION, nil)
+	case ':':
+		s.addToken(token.COLON, nil)
+	case '&':
+		s.addToken(token.AMPERSAND, nil)
+	case '|':
+		if s.match('>') {
+			s.addToken(token.PIPE_GREATER, nil)
+		} else {
+			s.addToken(token.PIPE, nil)
+		}
+	case '^':
+		s.addToken(token.CARET, nil)
+	case '~':
+		s.addToken(token.TILDE, nil)
 	case '!':
 		if s.match('=') {
 			s.addToken(token.BANG_EQUAL, nil)
@@ -64,46 +346,114 @@ func (s *Scanner) scanToken() {
 	case '=':
 		if s.match('=') {
 			s.addToken(token.EQUAL_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(token.FAT_ARROW, nil)
 		} else {
 			s.addToken(token.EQUAL, nil)
 		}
 	case '<':
 		if s.match('=') {
 			s.addToken(token.LESS_EQUAL, nil)
+		} else if s.match('<') {
+			s.addToken(token.LESS_LESS, nil)
 		} else {
 			s.addToken(token.LESS, nil)
 		}
 	case '>':
 		if s.match('=') {
 			s.addToken(token.GREATER_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(token.GREATER_GREATER, nil)
 		} else {
 			s.addToken(token.GREATER, nil)
 		}
-	case '\\':
-		if s.match('\\') {
-			for s.peek() != '\n' && !s.isAtEnd() {
-				s.advance()
-			}
+	case '/':
+		if s.match('/') {
+			s.lineComment()
+		} else if s.match('*') {
+			s.blockComment()
+		} else if s.match('=') {
+			s.addToken(token.SLASH_EQUAL, nil)
 		} else {
 			s.addToken(token.SLASH, nil)
 		}
 	case ' ':
 	case '\t':
 	case '\r':
+		s.match('\n')
 	case '\n':
-		s.line++
 	case '"':
-		s.string()
+		s.string('"')
 	case '\'':
-		s.string()
+		s.string('\'')
+	case '`':
+		s.rawString()
 	default:
 		if s.isDigit(c) {
-			s.number()
+			s.number(c)
+		} else if s.isAlpha(c) {
+			s.identifier()
 		} else {
-			error.Error(s.line, "Unexpected character: "+string(c))
-			s.HadError = true
+			s.illegal()
+		}
+	}
+}
+
+// illegal will consume a run of characters that cannot begin a token, and add them as a
+// single ILLEGAL token with one diagnostic.
+func (s *Scanner) illegal() {
+	for !s.isAtEnd() && s.isIllegal(s.peek()) {
+		s.advance()
+	}
+	// Invalid UTF-8 has been reported by advance, so only the rest of the run is reported here.
+	text := strings.ToValidUTF8(string(s.source[s.start:s.current]), "")
+	switch utf8.RuneCountInString(text) {
+	case 0:
+	case 1:
+		s.errorf(CodeUnexpectedCharacter, "Unexpected character: %s", text)
+	default:
+		s.errorf(CodeUnexpectedCharacter, "Unexpected characters: %s", text)
+	}
+	s.addToken(token.ILLEGAL, nil)
+}
+
+// isIllegal will check whether character cannot begin a token or trivia.
+func (s *Scanner) isIllegal(c rune) bool {
+	return !strings.ContainsRune(tokenStarts, c) && !s.isDigit(c) && !s.isAlpha(c)
+}
+
+// errorf will report an error diagnostic spanning the current token.
+func (s *Scanner) errorf(code string, format string, args ...interface{}) {
+	s.errorAt(s.startPosition(), code, format, args...)
+}
+
+// errorAt will report an error diagnostic spanning from start to the current position.
+func (s *Scanner) errorAt(start token.Position, code string, format string, args ...interface{}) {
+	s.report(error.Diagnostic{
+		Severity: error.SeverityError,
+		Span:     token.Span{Start: start, End: s.position()},
+		Code:     code,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// report will pass d to the Reporter, recording whether an error was seen.
+func (s *Scanner) report(d error.Diagnostic) {
+	if d.Severity == error.SeverityError {
+		s.HadError = true
+		s.errors++
+	}
+	r := s.Reporter
+	if r == nil {
+		r = error.Default
+	}
+	if s.MaxErrors > 0 {
+		if s.limit == nil {
+			s.limit = error.Limit(r, s.MaxErrors)
 		}
+		r = s.limit
 	}
+	r.Report(d)
 }
 
 // match will compare unconsumed character with expected character
@@ -112,32 +462,138 @@ func (s *Scanner) match(expected rune) bool {
 		return false
 	}
 
-	current, size := utf8.DecodeRune(s.source[s.current:])
+	s.fill(utf8.UTFMax)
+	current, _ := utf8.DecodeRune(s.source[s.current:])
 	if current != expected {
 		return false
 	}
-	s.current = s.current + size
+	s.advance()
 	return true
 }
 
-// string will consume all tokens contained within " or ', add string token, with literal string.
-func (s *Scanner) string() {
+// lineComment will consume the rest of a // comment. Comments starting with exactly three
+// slashes are documentation, and are added as DOC_COMMENT tokens whose literal is their text.
+func (s *Scanner) lineComment() {
+	doc := s.peek() == '/' && s.peekNext() != '/'
+	for s.peek() != '\n' && s.peek() != '\r' && !s.isAtEnd() {
+		s.advance()
+	}
+	if doc {
+		s.addToken(token.DOC_COMMENT, strings.TrimPrefix(string(s.source[s.start+3:s.current]), " "))
+	}
+}
+
+// blockComment will consume a /* */ comment, including any comments nested within it.
+func (s *Scanner) blockComment() {
+	depth := 1
+	for depth > 0 && !s.isAtEnd() {
+		switch c := s.advance(); {
+		case c == '/' && s.match('*'):
+			depth++
+		case c == '*' && s.match('/'):
+			depth--
+		}
+	}
+	if depth > 0 {
+		s.errorf(CodeUnterminatedComment, "Unterminated block comment.")
+	}
+}
+
+// string will consume a string closed by the quote it was opened with, and add a string
+// token whose literal has escape sequences decoded.
+func (s *Scanner) string(quote rune) {
 	literal := make([]rune, 0)
-	for s.peek() != '"' && s.peek() != '\'' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+	for s.peek() != quote && !s.isAtEnd() {
+		pos := s.position()
+		c := s.advance()
+		if c == '\\' {
+			var ok bool
+			if c, ok = s.escape(pos); !ok {
+				continue
+			}
 		}
+		literal = append(literal, c)
+	}
+	if s.isAtEnd() {
+		s.errorf(CodeUnterminatedString, "Unterminated string.")
+		s.addToken(token.ILLEGAL, nil)
+		return
+	}
+	s.advance()
+	s.addToken(token.STRING, string(literal))
+}
+
+// rawString will consume a backtick string, in which no escape sequences are recognised.
+func (s *Scanner) rawString() {
+	literal := make([]rune, 0)
+	for s.peek() != '`' && !s.isAtEnd() {
 		literal = append(literal, s.advance())
 	}
 	if s.isAtEnd() {
-		error.Error(s.line, "Unterminated string.")
+		s.errorf(CodeUnterminatedString, "Unterminated raw string.")
+		s.addToken(token.ILLEGAL, nil)
 		return
 	}
-	s.addToken(token.STRING, literal)
+	s.advance()
+	s.addToken(token.STRING, string(literal))
+}
+
+// escape will consume the escape sequence following a backslash at start, and return the
+// rune it stands for. Invalid sequences are reported, and return false.
+func (s *Scanner) escape(start token.Position) (rune, bool) {
+	if s.isAtEnd() {
+		return 0, false
+	}
+	c := s.advance()
+	switch c {
+	case 'n':
+		return '\n', true
+	case 't':
+		return '\t', true
+	case 'r':
+		return '\r', true
+	case '0':
+		return 0, true
+	case '\\', '"', '\'':
+		return c, true
+	case 'u':
+		return s.unicodeEscape(start)
+	}
+	s.errorAt(start, CodeInvalidEscape, "Unknown escape sequence: \\%c", c)
+	return 0, false
+}
+
+// unicodeEscape will consume the {XXXX} part of a \u{XXXX} escape, of one to six hex digits.
+func (s *Scanner) unicodeEscape(start token.Position) (rune, bool) {
+	if !s.match('{') {
+		s.errorAt(start, CodeInvalidEscape, "Invalid unicode escape: expected \\u{XXXX}")
+		return 0, false
+	}
+	var value rune
+	digits := 0
+	for s.isHexDigit(s.peek()) {
+		value = value*16 + rune(digitValue(s.advance()))
+		digits++
+	}
+	if digits == 0 || digits > 6 || !s.match('}') {
+		s.errorAt(start, CodeInvalidEscape, "Invalid unicode escape: expected one to six hex digits in \\u{XXXX}")
+		return 0, false
+	}
+	if value > unicode.MaxRune || (value >= 0xD800 && value <= 0xDFFF) {
+		s.errorAt(start, CodeInvalidEscape, "Invalid unicode escape: U+%X is not a valid code point", value)
+		return 0, false
+	}
+	return value, true
+}
+
+// isHexDigit will check whether character is a hexadecimal digit.
+func (s *Scanner) isHexDigit(c rune) bool {
+	return ('0' <= c && c <= '9') || ('a' <= c && c <= 'f') || ('A' <= c && c <= 'F')
 }
 
 // isAtEnd will return whether current is last.
 func (s *Scanner) isAtEnd() bool {
+	s.fill(1)
 	if s.current >= len(s.source) {
 		return true
 	}
@@ -145,23 +601,70 @@ func (s *Scanner) isAtEnd() bool {
 }
 
 // advance will consume the current token, and return the consumed token.
+// Line and column are tracked here so every consumer stays in step. A line ends at \n, \r\n
+// or a lone \r. Bytes that are not valid UTF-8 are reported, and returned as utf8.RuneError.
 func (s *Scanner) advance() rune {
+	s.fill(utf8.UTFMax)
 	r, size := utf8.DecodeRune(s.source[s.current:])
+	if r == utf8.RuneError && size == 1 {
+		start := s.position()
+		s.current++
+		s.column++
+		s.errorAt(start, CodeInvalidUTF8, "Invalid UTF-8 encoding: byte 0x%02X", s.source[s.current-1])
+		return r
+	}
 	s.current = s.current + size
+	if r == '\n' || (r == '\r' && s.peek() != '\n') {
+		s.line++
+		s.column = 0
+		if s.file != nil {
+			s.file.AddLine(s.offset + s.current)
+		}
+	} else {
+		s.column++
+	}
 	return r
 }
 
+// position will return the position of the next unconsumed character.
+func (s *Scanner) position() token.Position {
+	return token.Position{Filename: s.filename(), Offset: s.offset + s.current, Line: s.line, Column: s.column + 1}
+}
+
+// startPosition will return the position of the first character of the current token.
+func (s *Scanner) startPosition() token.Position {
+	return token.Position{Filename: s.filename(), Offset: s.offset + s.start, Line: s.startLine, Column: s.startColumn + 1}
+}
+
+// filename will return the name of the scanned file, or "" when scanning a bare string.
+func (s *Scanner) filename() string {
+	if s.file == nil {
+		return s.name
+	}
+	return s.file.Name()
+}
+
+// pos will return the compact Pos for offset, or token.NoPos when scanning a bare string.
+func (s *Scanner) pos(offset int) token.Pos {
+	if s.file == nil {
+		return token.NoPos
+	}
+	return s.file.Pos(offset)
+}
+
 // peek will return the current token, without consuming
 func (s *Scanner) peek() rune {
 	if s.isAtEnd() {
 		return '\000'
 	}
+	s.fill(utf8.UTFMax)
 	r, _ := utf8.DecodeRune(s.source[s.current:])
 	return r
 }
 
 // peekNext will return the next token without consuming.
 func (s *Scanner) peekNext() rune {
+	s.fill(2 * utf8.UTFMax)
 	if s.current+1 >= len(s.source) {
 		return '\000'
 	}
@@ -170,36 +673,202 @@ func (s *Scanner) peekNext() rune {
 	return nextRune
 }
 
-// isDigit will check whether character is an integer.
+// isDigit will check whether character is a decimal digit. Only ASCII digits are part
+// of the grammar; other Unicode digits are not accepted in numbers or identifiers.
 func (s *Scanner) isDigit(c rune) bool {
-	return unicode.IsDigit(c)
+	return '0' <= c && c <= '9'
+}
+
+// isAlpha will check whether character may start an identifier.
+func (s *Scanner) isAlpha(c rune) bool {
+	return c == '_' || unicode.IsLetter(c)
+}
+
+// isAlphaNumeric will check whether character may continue an identifier.
+func (s *Scanner) isAlphaNumeric(c rune) bool {
+	return s.isAlpha(c) || s.isDigit(c)
 }
 
-// number will consume, and finally add a number token, with float64 literal.
-func (s *Scanner) number() {
-	for s.isDigit(s.peek()) {
+// identifier will consume an identifier, and add either a keyword or identifier token.
+func (s *Scanner) identifier() {
+	for s.isAlphaNumeric(s.peek()) {
 		s.advance()
 	}
-	if s.peek() == '.' && s.isDigit(s.peekNext()) {
+	text := string(s.source[s.start:s.current])
+	if tokenType, ok := keywords[text]; ok {
+		s.addToken(tokenType, keywordLiterals[tokenType])
+		return
+	}
+	s.addToken(token.IDENTIFIER, nil)
+}
+
+// number will consume a number literal whose first digit is first, and add a number token.
+// Literals may be decimal, with optional fraction and exponent, or 0x, 0o and 0b prefixed
+// integers; single underscores may separate digits. The literal has the separators removed.
+// Malformed literals, and those out of range, are added as ILLEGAL tokens.
+func (s *Scanner) number(first rune) {
+	errors := s.errors
+	base := 10
+	if first == '0' {
+		switch s.peek() {
+		case 'x', 'X':
+			base = 16
+		case 'o', 'O':
+			base = 8
+		case 'b', 'B':
+			base = 2
+		}
+	}
+
+	if base != 10 {
 		s.advance()
-		for s.isDigit(s.peek()) {
+		if s.digits(base, false) == 0 {
+			s.errorf(CodeMalformedNumber, "Malformed number: %s literal has no digits", baseNames[base])
+		}
+	} else {
+		s.digits(base, true)
+		if s.peek() == '.' {
+			next := s.peekNext()
+			if s.isDigit(next) || next == '_' {
+				s.advance()
+				s.digits(base, false)
+			} else if !s.isAlpha(next) && next != '.' {
+				// A trailing '.' before an identifier is property access, and '..' is left alone.
+				s.advance()
+				s.errorf(CodeMalformedNumber, "Malformed number: expected digits after decimal point")
+			}
+		}
+		if c := s.peek(); c == 'e' || c == 'E' {
+			s.advance()
+			if c := s.peek(); c == '+' || c == '-' {
+				s.advance()
+			}
+			if s.digits(base, false) == 0 {
+				s.errorf(CodeMalformedNumber, "Malformed number: exponent has no digits")
+			}
+		}
+	}
+
+	if s.isAlphaNumeric(s.peek()) {
+		suffixStart := s.current
+		for s.isAlphaNumeric(s.peek()) {
 			s.advance()
 		}
+		s.errorf(CodeMalformedNumber, "Malformed number: invalid suffix %q", string(s.source[suffixStart:s.current]))
+	}
+
+	if s.errors == errors {
+		text := strings.Replace(string(s.source[s.start:s.current]), "_", "", -1)
+		value := s.numberValue(text, base)
+		if s.errors == errors {
+			s.addToken(token.NUMBER, value)
+			return
+		}
 	}
-	// this seems a bit convoluted just to cast []byte into []rune
-	literal := []rune(string(s.source[s.start:s.current]))
-	s.addToken(token.NUMBER, literal)
+	s.addToken(token.ILLEGAL, nil)
+}
+
+// numberValue will convert the text of a well-formed number literal into an int64, or a
+// float64 when it has a fraction or exponent. Values that do not fit are reported, and nil.
+// Floats so small that they lose precision are kept, with a warning.
+func (s *Scanner) numberValue(text string, base int) interface{} {
+	if base != 10 || !strings.ContainsAny(text, ".eE") {
+		if base != 10 {
+			text = text[2:]
+		}
+		value, err := strconv.ParseInt(text, base, 64)
+		if err != nil {
+			s.errorf(CodeNumberRange, "Number literal overflows a 64-bit integer.")
+			return nil
+		}
+		return value
+	}
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		s.errorf(CodeNumberRange, "Number literal overflows a 64-bit float.")
+		return nil
+	}
+	if value == 0 && strings.IndexAny(strings.SplitN(strings.ToLower(text), "e", 2)[0], "123456789") >= 0 {
+		s.errorf(CodeNumberRange, "Number literal is too small to represent, and would lose all precision.")
+		return nil
+	}
+	if value != 0 && math.Abs(value) < smallestNormal {
+		pos := s.startPosition()
+		s.report(error.Diagnostic{
+			Severity: error.SeverityWarning,
+			Span:     token.Span{Start: pos, End: s.position()},
+			Code:     CodeNumberPrecision,
+			Message:  "Number literal is below the normal float range, and loses precision.",
+		})
+	}
+	return value
+}
+
+// digits will consume the digits of a number in base, allowing single underscores between
+// digits, and return how many digits were consumed. afterDigit reports whether a digit was
+// consumed just before. Digits too large for the base are consumed, and reported.
+func (s *Scanner) digits(base int, afterDigit bool) int {
+	count := 0
+	for {
+		c := s.peek()
+		if c == '_' {
+			pos := s.position()
+			underscores := 0
+			for s.peek() == '_' {
+				s.advance()
+				underscores++
+			}
+			if underscores > 1 || !afterDigit || !s.isDigitOf(s.peek(), base) {
+				s.errorAt(pos, CodeMalformedNumber, "Malformed number: '_' must separate digits")
+			}
+			afterDigit = false
+			continue
+		}
+		if !s.isDigitOf(c, base) {
+			return count
+		}
+		pos := s.position()
+		s.advance()
+		if value := digitValue(c); value >= base {
+			s.errorAt(pos, CodeMalformedNumber, "Malformed number: invalid digit '%c' in %s literal", c, baseNames[base])
+		}
+		count++
+		afterDigit = true
+	}
+}
+
+// isDigitOf will check whether character belongs to a number written in base. Prefixed
+// literals claim every ASCII letter and digit, so that out of range digits are reported.
+func (s *Scanner) isDigitOf(c rune, base int) bool {
+	if base == 10 {
+		return s.isDigit(c)
+	}
+	return digitValue(c) >= 0
+}
+
+// digitValue will return the value of an ASCII letter or digit as a digit, or -1.
+func digitValue(c rune) int {
+	switch {
+	case '0' <= c && c <= '9':
+		return int(c - '0')
+	case 'a' <= c && c <= 'z':
+		return int(c-'a') + 10
+	case 'A' <= c && c <= 'Z':
+		return int(c-'A') + 10
+	}
+	return -1
 }
 
 // addToken will add token type and lexeme to returned tokens array.
-func (s *Scanner) addToken(tokenType token.TokenType, literal []rune) []token.Token {
-	var lexeme string
-	if literal != nil {
-		lexeme = string(literal)
-	} else {
-		lexeme = string(s.source[s.start:s.current])
+func (s *Scanner) addToken(tokenType token.TokenType, literal interface{}) []token.Token {
+	lexeme := string(s.source[s.start:s.current])
+	span := token.Span{
+		Start: s.startPosition(),
+		End:   s.position(),
 	}
-	token := token.Token{Type: tokenType, Lexeme: lexeme, Literal: literal, Line: s.line}
+	token := token.Token{Type: tokenType, Lexeme: lexeme, Literal: literal, Line: s.line, Span: span, Pos: s.pos(span.Start.Offset), LeadingTrivia: s.trivia}
 	s.tokens = append(s.tokens, token)
+	s.trivia = nil
+	s.trailing = s.Mode&ScanTrivia != 0
 	return s.tokens
 }
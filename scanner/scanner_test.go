@@ -2,7 +2,10 @@ package scanner
 
 import (
 	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
+	"xolog/error"
 	"xolog/token"
 )
 
@@ -32,12 +35,16 @@ func TestNewScanner(t *testing.T) {
 
 func TestScanner_ScanTokens(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -45,14 +52,22 @@ func TestScanner_ScanTokens(t *testing.T) {
 		want   []token.Token
 	}{
 		{
-			name:   "Unexpected characters, Return token array containing EOF only.",
-			fields: fields{source: []byte("TE"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
+			name:   "Unexpected characters, Return one ILLEGAL token and EOF.",
+			fields: fields{source: []byte("@#"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
 			want: []token.Token{
+				{
+					Type:    token.ILLEGAL,
+					Lexeme:  "@#",
+					Literal: nil,
+					Line:    1,
+					Span:    span(0, 1, 1, 2, 1, 3),
+				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 2, 1, 3),
 				},
 			},
 		},
@@ -65,18 +80,21 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "!=",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 2, 1, 3),
 				},
 				{
 					Type:    token.BANG,
 					Lexeme:  "!",
 					Literal: nil,
 					Line:    1,
+					Span:    span(3, 1, 4, 4, 1, 5),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(4, 1, 5, 4, 1, 5),
 				},
 			},
 		},
@@ -89,12 +107,14 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  ",",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 1, 1, 2),
 				},
 			},
 		},
@@ -107,12 +127,14 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  ".",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 1, 1, 2),
 				},
 			},
 		},
@@ -125,30 +147,35 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "<=",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 2, 1, 3),
 				},
 				{
 					Type:    token.LESS,
 					Lexeme:  "<",
 					Literal: nil,
 					Line:    1,
+					Span:    span(3, 1, 4, 4, 1, 5),
 				},
 				{
 					Type:    token.GREATER,
 					Lexeme:  ">",
 					Literal: nil,
 					Line:    1,
+					Span:    span(5, 1, 6, 6, 1, 7),
 				},
 				{
 					Type:    token.GREATER_EQUAL,
 					Lexeme:  ">=",
 					Literal: nil,
 					Line:    1,
+					Span:    span(7, 1, 8, 9, 1, 10),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(9, 1, 10, 9, 1, 10),
 				},
 			},
 		},
@@ -161,12 +188,14 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "==",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 2, 1, 3),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 2, 1, 3),
 				},
 			},
 		},
@@ -179,18 +208,21 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "==",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 2, 1, 3),
 				},
 				{
 					Type:    token.EQUAL,
 					Lexeme:  "=",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 3, 1, 4),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(3, 1, 4, 3, 1, 4),
 				},
 			},
 		},
@@ -203,12 +235,14 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "{",
 					Literal: nil,
 					Line:    2,
+					Span:    span(6, 2, 1, 7, 2, 2),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    2,
+					Span:    span(7, 2, 2, 7, 2, 2),
 				},
 			},
 		},
@@ -221,30 +255,35 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "(",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.LEFT_BRACE,
 					Lexeme:  "{",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 2, 1, 3),
 				},
 				{
 					Type:    token.RIGHT_BRACE,
 					Lexeme:  "}",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 3, 1, 4),
 				},
 				{
 					Type:    token.RIGHT_PAREN,
 					Lexeme:  ")",
 					Literal: nil,
 					Line:    1,
+					Span:    span(3, 1, 4, 4, 1, 5),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(4, 1, 5, 4, 1, 5),
 				},
 			},
 		},
@@ -257,18 +296,21 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "(",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.LEFT_BRACE,
 					Lexeme:  "{",
 					Literal: nil,
 					Line:    2,
+					Span:    span(2, 2, 1, 3, 2, 2),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    2,
+					Span:    span(3, 2, 2, 3, 2, 2),
 				},
 			},
 		},
@@ -281,18 +323,21 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  "-",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.PLUS,
 					Lexeme:  "+",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 2, 1, 3),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 2, 1, 3),
 				},
 			},
 		},
@@ -305,42 +350,164 @@ func TestScanner_ScanTokens(t *testing.T) {
 					Lexeme:  ";",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.STAR,
 					Lexeme:  "*",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 2, 1, 3),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(2, 1, 3, 2, 1, 3),
 				},
 			},
 		},
 		{
-			name:   "SLASH, EOF token array, ignore \\ comment",
-			fields: fields{source: []byte("\\,\\\\test"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
+			name:   "SLASH, EOF token array, ignore // comment",
+			fields: fields{source: []byte("/,//test"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
 			want: []token.Token{
 				{
 					Type:    token.SLASH,
-					Lexeme:  "\\",
+					Lexeme:  "/",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 				{
 					Type:    token.COMMA,
 					Lexeme:  ",",
 					Literal: nil,
 					Line:    1,
+					Span:    span(1, 1, 2, 2, 1, 3),
+				},
+				{
+					Type:    token.EOF,
+					Lexeme:  "\000",
+					Literal: nil,
+					Line:    1,
+					Span:    span(8, 1, 9, 8, 1, 9),
+				},
+			},
+		},
+		{
+			name:   "VAR, IDENTIFIER, EQUAL, NUMBER, SEMICOLON token array",
+			fields: fields{source: []byte("var x = 1;"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
+			want: []token.Token{
+				{
+					Type:    token.VAR,
+					Lexeme:  "var",
+					Literal: nil,
+					Line:    1,
+					Span:    span(0, 1, 1, 3, 1, 4),
+				},
+				{
+					Type:    token.IDENTIFIER,
+					Lexeme:  "x",
+					Literal: nil,
+					Line:    1,
+					Span:    span(4, 1, 5, 5, 1, 6),
+				},
+				{
+					Type:    token.EQUAL,
+					Lexeme:  "=",
+					Literal: nil,
+					Line:    1,
+					Span:    span(6, 1, 7, 7, 1, 8),
+				},
+				{
+					Type:    token.NUMBER,
+					Lexeme:  "1",
+					Literal: int64(1),
+					Line:    1,
+					Span:    span(8, 1, 9, 9, 1, 10),
+				},
+				{
+					Type:    token.SEMICOLON,
+					Lexeme:  ";",
+					Literal: nil,
+					Line:    1,
+					Span:    span(9, 1, 10, 10, 1, 11),
 				},
 				{
 					Type:    token.EOF,
 					Lexeme:  "\000",
 					Literal: nil,
 					Line:    1,
+					Span:    span(10, 1, 11, 10, 1, 11),
+				},
+			},
+		},
+		{
+			name:   "Columns count runes, offsets count bytes",
+			fields: fields{source: []byte("größe\n  +"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
+			want: []token.Token{
+				{
+					Type:    token.IDENTIFIER,
+					Lexeme:  "größe",
+					Literal: nil,
+					Line:    1,
+					Span:    span(0, 1, 1, 7, 1, 6),
+				},
+				{
+					Type:    token.PLUS,
+					Lexeme:  "+",
+					Literal: nil,
+					Line:    2,
+					Span:    span(10, 2, 3, 11, 2, 4),
+				},
+				{
+					Type:    token.EOF,
+					Lexeme:  "\000",
+					Literal: nil,
+					Line:    2,
+					Span:    span(11, 2, 4, 11, 2, 4),
+				},
+			},
+		},
+		{
+			name:   "Keywords, underscores and unicode letters in identifiers",
+			fields: fields{source: []byte("while _count2 größe orchid"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
+			want: []token.Token{
+				{
+					Type:    token.WHILE,
+					Lexeme:  "while",
+					Literal: nil,
+					Line:    1,
+					Span:    span(0, 1, 1, 5, 1, 6),
+				},
+				{
+					Type:    token.IDENTIFIER,
+					Lexeme:  "_count2",
+					Literal: nil,
+					Line:    1,
+					Span:    span(6, 1, 7, 13, 1, 14),
+				},
+				{
+					Type:    token.IDENTIFIER,
+					Lexeme:  "größe",
+					Literal: nil,
+					Line:    1,
+					Span:    span(14, 1, 15, 21, 1, 20),
+				},
+				{
+					Type:    token.IDENTIFIER,
+					Lexeme:  "orchid",
+					Literal: nil,
+					Line:    1,
+					Span:    span(22, 1, 21, 28, 1, 27),
+				},
+				{
+					Type:    token.EOF,
+					Lexeme:  "\000",
+					Literal: nil,
+					Line:    1,
+					Span:    span(28, 1, 27, 28, 1, 27),
 				},
 			},
 		},
@@ -348,12 +515,16 @@ func TestScanner_ScanTokens(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.ScanTokens(); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Scanner.ScanTokens() = %v, want %v", got, tt.want)
@@ -364,12 +535,16 @@ func TestScanner_ScanTokens(t *testing.T) {
 
 func TestScanner_scanToken(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -378,18 +553,25 @@ func TestScanner_scanToken(t *testing.T) {
 	}{
 		{
 			name:   "Will advance token, and log error.",
-			fields: fields{source: []byte("TE"), start: 0, current: 0, line: 1, tokens: make([]token.Token, len("TE")+1)},
-			want:   Scanner{source: []byte("TE"), start: 0, current: 1, line: 1, tokens: make([]token.Token, len("TE")+1), HadError: true},
+			fields: fields{source: []byte("@#"), start: 0, current: 0, line: 1, tokens: make([]token.Token, len("@#")+1)},
+			want: Scanner{source: []byte("@#"), start: 0, current: 2, line: 1, column: 2, startLine: 1, tokens: append(make([]token.Token, len("@#")+1), token.Token{
+				Type:    token.ILLEGAL,
+				Lexeme:  "@#",
+				Literal: nil,
+				Line:    1,
+				Span:    span(0, 1, 1, 2, 1, 3),
+			}), errors: 1, HadError: true},
 		},
 		{
 			name:   "Will advance, and create matching token within first array index..",
 			fields: fields{source: []byte("{"), start: 0, current: 0, line: 1, tokens: []token.Token{}},
-			want: Scanner{source: []byte("{"), start: 0, current: 1, line: 1, tokens: []token.Token{
+			want: Scanner{source: []byte("{"), start: 0, current: 1, line: 1, column: 1, startLine: 1, tokens: []token.Token{
 				{
 					Type:    token.LEFT_BRACE,
 					Lexeme:  "{",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 			}, HadError: false},
 		},
@@ -397,12 +579,16 @@ func TestScanner_scanToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			s.scanToken()
 			if got := s; !reflect.DeepEqual(got, tt.want) {
@@ -415,12 +601,16 @@ func TestScanner_scanToken(t *testing.T) {
 
 func TestScanner_match(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	type args struct {
 		expected rune
@@ -487,12 +677,16 @@ func TestScanner_match(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.match(tt.args.expected); got != tt.want {
 				t.Errorf("Scanner.match() = %v, want %v", got, tt.want)
@@ -503,12 +697,16 @@ func TestScanner_match(t *testing.T) {
 
 func TestScanner_isAtEnd(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -543,12 +741,16 @@ func TestScanner_isAtEnd(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.isAtEnd(); got != tt.want {
 				t.Errorf("Scanner.isAtEnd() = %v, want %v", got, tt.want)
@@ -559,12 +761,16 @@ func TestScanner_isAtEnd(t *testing.T) {
 
 func TestScanner_advance(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -599,12 +805,16 @@ func TestScanner_advance(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.advance(); got != tt.want {
 				t.Errorf("Scanner.advance() = %v, want %v", got, tt.want)
@@ -615,12 +825,16 @@ func TestScanner_advance(t *testing.T) {
 
 func TestScanner_peek(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -679,12 +893,16 @@ func TestScanner_peek(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.peek(); got != tt.want {
 				t.Errorf("Scanner.peek() = %v, want %v", got, tt.want)
@@ -695,16 +913,20 @@ func TestScanner_peek(t *testing.T) {
 
 func TestScanner_addToken(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	type args struct {
 		tokenType token.TokenType
-		literal   []rune
+		literal   interface{}
 	}
 	tests := []struct {
 		name   string
@@ -715,12 +937,14 @@ func TestScanner_addToken(t *testing.T) {
 		{
 			name: "Add comma token.",
 			fields: fields{
-				source:   []byte(","),
-				start:    0,
-				current:  1,
-				line:     1,
-				tokens:   []token.Token{},
-				HadError: false,
+				source:    []byte(","),
+				start:     0,
+				current:   1,
+				line:      1,
+				column:    1,
+				startLine: 1,
+				tokens:    []token.Token{},
+				HadError:  false,
 			},
 			args: args{token.COMMA, nil},
 			want: []token.Token{
@@ -729,6 +953,7 @@ func TestScanner_addToken(t *testing.T) {
 					Lexeme:  ",",
 					Literal: nil,
 					Line:    1,
+					Span:    span(0, 1, 1, 1, 1, 2),
 				},
 			},
 		},
@@ -736,12 +961,16 @@ func TestScanner_addToken(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.addToken(tt.args.tokenType, tt.args.literal); !reflect.DeepEqual(got, tt.want) {
 				t.Errorf("Scanner.addToken() = %v, want %v", got, tt.want)
@@ -752,12 +981,16 @@ func TestScanner_addToken(t *testing.T) {
 
 func TestScanner_string(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -775,16 +1008,19 @@ func TestScanner_string(t *testing.T) {
 				HadError: false,
 			},
 			want: Scanner{
-				source:  []byte("'test'"),
-				start:   0,
-				current: 5,
-				line:    1,
+				source:    []byte("'test'"),
+				start:     0,
+				current:   6,
+				line:      1,
+				column:    6,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.STRING,
-						Lexeme:  "test",
-						Literal: []rune("test"),
+						Lexeme:  "'test'",
+						Literal: "test",
 						Line:    1,
+						Span:    span(0, 1, 1, 6, 1, 7),
 					},
 				},
 				HadError: false,
@@ -801,16 +1037,19 @@ func TestScanner_string(t *testing.T) {
 				HadError: false,
 			},
 			want: Scanner{
-				source:  []byte("\"test \n more\""),
-				start:   0,
-				current: 12,
-				line:    2,
+				source:    []byte("\"test \n more\""),
+				start:     0,
+				current:   13,
+				line:      2,
+				column:    6,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.STRING,
-						Lexeme:  "test \n more",
-						Literal: []rune("test \n more"),
+						Lexeme:  "\"test \n more\"",
+						Literal: "test \n more",
 						Line:    2,
+						Span:    span(0, 1, 1, 13, 2, 7),
 					},
 				},
 				HadError: false,
@@ -827,12 +1066,23 @@ func TestScanner_string(t *testing.T) {
 				HadError: false,
 			},
 			want: Scanner{
-				source:   []byte("'test"),
-				start:    0,
-				current:  5,
-				line:     1,
-				tokens:   []token.Token{},
-				HadError: false,
+				source:    []byte("'test"),
+				start:     0,
+				current:   5,
+				line:      1,
+				column:    5,
+				startLine: 1,
+				tokens: []token.Token{
+					{
+						Type:    token.ILLEGAL,
+						Lexeme:  "'test",
+						Literal: nil,
+						Line:    1,
+						Span:    span(0, 1, 1, 5, 1, 6),
+					},
+				},
+				errors:   1,
+				HadError: true,
 			},
 		},
 		{
@@ -846,16 +1096,19 @@ func TestScanner_string(t *testing.T) {
 				HadError: false,
 			},
 			want: Scanner{
-				source:  []byte(`"h,ello"`),
-				start:   0,
-				current: 7,
-				line:    1,
+				source:    []byte(`"h,ello"`),
+				start:     0,
+				current:   8,
+				line:      1,
+				column:    8,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.STRING,
-						Lexeme:  `h,ello`,
-						Literal: []rune("h,ello"),
+						Lexeme:  `"h,ello"`,
+						Literal: "h,ello",
 						Line:    1,
+						Span:    span(0, 1, 1, 8, 1, 9),
 					},
 				},
 				HadError: false,
@@ -872,16 +1125,19 @@ func TestScanner_string(t *testing.T) {
 				HadError: false,
 			},
 			want: Scanner{
-				source:  []byte(`"h,ello"{`),
-				start:   0,
-				current: 7,
-				line:    1,
+				source:    []byte(`"h,ello"{`),
+				start:     0,
+				current:   8,
+				line:      1,
+				column:    8,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.STRING,
-						Lexeme:  `h,ello`,
-						Literal: []rune("h,ello"),
+						Lexeme:  `"h,ello"`,
+						Literal: "h,ello",
 						Line:    1,
+						Span:    span(0, 1, 1, 8, 1, 9),
 					},
 				},
 				HadError: false,
@@ -891,12 +1147,16 @@ func TestScanner_string(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			// string is called by scanToken as cases all start with opening quote
 			s.scanToken()
@@ -909,12 +1169,16 @@ func TestScanner_string(t *testing.T) {
 
 func TestScanner_peekNext(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -961,12 +1225,16 @@ func TestScanner_peekNext(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.peekNext(); got != tt.want {
 				t.Errorf("Scanner.peekNext() = %v, want %v", got, tt.want)
@@ -977,12 +1245,16 @@ func TestScanner_peekNext(t *testing.T) {
 
 func TestScanner_isDigit(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	type args struct {
 		c rune
@@ -1023,12 +1295,16 @@ func TestScanner_isDigit(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			if got := s.isDigit(tt.args.c); got != tt.want {
 				t.Errorf("Scanner.isDigit() = %v, want %v", got, tt.want)
@@ -1039,12 +1315,16 @@ func TestScanner_isDigit(t *testing.T) {
 
 func TestScanner_number(t *testing.T) {
 	type fields struct {
-		source   []byte
-		start    int
-		current  int
-		line     int
-		tokens   []token.Token
-		HadError bool
+		source      []byte
+		start       int
+		current     int
+		line        int
+		column      int
+		startLine   int
+		startColumn int
+		tokens      []token.Token
+		errors      int
+		HadError    bool
 	}
 	tests := []struct {
 		name   string
@@ -1063,22 +1343,26 @@ func TestScanner_number(t *testing.T) {
 				HadError: false,
 			},
 			want: &Scanner{
-				source:  []byte("1.02"),
-				start:   0,
-				current: 4,
-				line:    1,
+				source:    []byte("1.02"),
+				start:     0,
+				current:   4,
+				line:      1,
+				column:    4,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.NUMBER,
 						Lexeme:  "1.02",
-						Literal: []rune("1.02"),
+						Literal: 1.02,
 						Line:    1,
+						Span:    span(0, 1, 1, 4, 1, 5),
 					},
 					{
 						Type:    token.EOF,
 						Lexeme:  "\000",
 						Literal: nil,
 						Line:    1,
+						Span:    span(4, 1, 5, 4, 1, 5),
 					},
 				},
 				HadError: false,
@@ -1095,22 +1379,26 @@ func TestScanner_number(t *testing.T) {
 				HadError: false,
 			},
 			want: &Scanner{
-				source:  []byte("102"),
-				start:   0,
-				current: 3,
-				line:    1,
+				source:    []byte("102"),
+				start:     0,
+				current:   3,
+				line:      1,
+				column:    3,
+				startLine: 1,
 				tokens: []token.Token{
 					{
 						Type:    token.NUMBER,
 						Lexeme:  "102",
-						Literal: []rune("102"),
+						Literal: int64(102),
 						Line:    1,
+						Span:    span(0, 1, 1, 3, 1, 4),
 					},
 					{
 						Type:    token.EOF,
 						Lexeme:  "\000",
 						Literal: nil,
 						Line:    1,
+						Span:    span(3, 1, 4, 3, 1, 4),
 					},
 				},
 				HadError: false,
@@ -1120,12 +1408,16 @@ func TestScanner_number(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			s := &Scanner{
-				source:   tt.fields.source,
-				start:    tt.fields.start,
-				current:  tt.fields.current,
-				line:     tt.fields.line,
-				tokens:   tt.fields.tokens,
-				HadError: tt.fields.HadError,
+				source:      tt.fields.source,
+				start:       tt.fields.start,
+				current:     tt.fields.current,
+				line:        tt.fields.line,
+				column:      tt.fields.column,
+				startLine:   tt.fields.startLine,
+				startColumn: tt.fields.startColumn,
+				tokens:      tt.fields.tokens,
+				errors:      tt.fields.errors,
+				HadError:    tt.fields.HadError,
 			}
 			s.ScanTokens()
 			if got := s; !reflect.DeepEqual(got, tt.want) {
@@ -1134,3 +1426,570 @@ func TestScanner_number(t *testing.T) {
 		})
 	}
 }
+
+func TestScanner_isAlpha(t *testing.T) {
+	tests := []struct {
+		name string
+		c    rune
+		want bool
+	}{
+		{name: "Will be true for ASCII letter", c: 'a', want: true},
+		{name: "Will be true for underscore", c: '_', want: true},
+		{name: "Will be true for unicode letter", c: 'ß', want: true},
+		{name: "Will be false for digit", c: '1', want: false},
+		{name: "Will be false for punctuation", c: '{', want: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &Scanner{}
+			if got := s.isAlpha(tt.c); got != tt.want {
+				t.Errorf("Scanner.isAlpha() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// span builds a token.Span from start and end offset, line and column.
+func span(startOffset, startLine, startColumn, endOffset, endLine, endColumn int) token.Span {
+	return token.Span{
+		Start: token.Position{Offset: startOffset, Line: startLine, Column: startColumn},
+		End:   token.Position{Offset: endOffset, Line: endLine, Column: endColumn},
+	}
+}
+
+func TestNewFileScanner(t *testing.T) {
+	fset := token.NewFileSet()
+	fset.AddFile("other.xo", []byte("nil;"))
+	file := fset.AddFile("main.xo", []byte("var a;\n  a;"))
+	tokens := NewFileScanner(file).ScanTokens()
+
+	if got, want := file.LineCount(), 2; got != want {
+		t.Fatalf("File.LineCount() = %v, want %v", got, want)
+	}
+	want := []string{"main.xo:1:1", "main.xo:1:5", "main.xo:1:6", "main.xo:2:3", "main.xo:2:4", "main.xo:2:5"}
+	if len(tokens) != len(want) {
+		t.Fatalf("ScanTokens() returned %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, tok := range tokens {
+		if got := tok.Span.Start.String(); got != want[i] {
+			t.Errorf("token %d Span.Start = %v, want %v", i, got, want[i])
+		}
+		if got := fset.Position(tok.Pos).String(); got != want[i] {
+			t.Errorf("token %d FileSet.Position(Pos) = %v, want %v", i, got, want[i])
+		}
+	}
+}
+
+func TestNewFileScanner_trailingNewline(t *testing.T) {
+	fset := token.NewFileSet()
+	file := fset.AddFile("main.xo", []byte("print 1;\n"))
+	tokens := NewFileScanner(file).ScanTokens()
+
+	eof := tokens[len(tokens)-1]
+	if got, want := fset.Position(eof.Pos), eof.Span.Start; got != want {
+		t.Errorf("FileSet.Position(EOF.Pos) = %v, want %v", got, want)
+	}
+	if got, want := eof.Span.Start.String(), "main.xo:2:1"; got != want {
+		t.Errorf("EOF Span.Start = %v, want %v", got, want)
+	}
+}
+
+func TestScanner_Reporter(t *testing.T) {
+	diagnostics := error.List{}
+	s := NewScanner("(@\n'open")
+	s.Reporter = &diagnostics
+	s.ScanTokens()
+
+	want := error.List{
+		{
+			Severity: error.SeverityError,
+			Span:     span(1, 1, 2, 2, 1, 3),
+			Code:     CodeUnexpectedCharacter,
+			Message:  "Unexpected character: @",
+		},
+		{
+			Severity: error.SeverityError,
+			Span:     span(3, 2, 1, 8, 2, 6),
+			Code:     CodeUnterminatedString,
+			Message:  "Unterminated string.",
+		},
+	}
+	if !reflect.DeepEqual(diagnostics, want) {
+		t.Errorf("Scanner.Reporter received %v, want %v", diagnostics, want)
+	}
+	if !s.HadError {
+		t.Errorf("Scanner.HadError = false, want true")
+	}
+}
+
+func TestScanner_Next(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "Empty source", source: ""},
+		{name: "Declarations over several lines", source: "var größe = 1.5;\n  print größe >= 2;\n"},
+		{name: "Strings spanning lines", source: "'a\nb' {}"},
+		{name: "Unexpected characters", source: "a @ b"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := NewScanner(tt.source).ScanTokens()
+			s := NewReaderScanner("", iotest.OneByteReader(strings.NewReader(tt.source)))
+			var got []token.Token
+			for tok := s.Next(); ; tok = s.Next() {
+				got = append(got, tok)
+				if tok.Type == token.EOF {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Scanner.Next() = %v, want %v", got, want)
+			}
+			if eof := s.Next(); eof.Type != token.EOF {
+				t.Errorf("Scanner.Next() after EOF = %v, want EOF", eof)
+			}
+		})
+	}
+}
+
+func TestScanner_Next_boundedBuffer(t *testing.T) {
+	line := "var value = 12345; print value;\n"
+	lines := 10000
+	s := NewReaderScanner("big.xo", strings.NewReader(strings.Repeat(line, lines)))
+	count := 0
+	var last token.Token
+	for tok := s.Next(); tok.Type != token.EOF; tok = s.Next() {
+		count++
+		last = tok
+	}
+	if want := 8 * lines; count != want {
+		t.Errorf("Scanner.Next() produced %d tokens, want %d", count, want)
+	}
+	if want := (token.Position{Filename: "big.xo", Offset: len(line)*lines - 2, Line: lines, Column: 31}); last.Span.Start != want {
+		t.Errorf("last token starts at %v, want %v", last.Span.Start, want)
+	}
+	if cap(s.source) > 4*chunkSize {
+		t.Errorf("Scanner buffer grew to %d bytes, want at most %d", cap(s.source), 4*chunkSize)
+	}
+}
+
+func TestScanner_Next_readError(t *testing.T) {
+	diagnostics := error.List{}
+	r := iotest.DataErrReader(iotest.TimeoutReader(strings.NewReader("(")))
+	s := NewReaderScanner("broken.xo", r)
+	s.Reporter = &diagnostics
+	for s.Next().Type != token.EOF {
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Code != CodeReadError {
+		t.Fatalf("Scanner.Reporter received %v, want one %s diagnostic", diagnostics, CodeReadError)
+	}
+	if !strings.Contains(diagnostics[0].Message, iotest.ErrTimeout.Error()) {
+		t.Errorf("diagnostic message = %q, want it to mention %v", diagnostics[0].Message, iotest.ErrTimeout)
+	}
+}
+
+func TestScanner_stringLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		literal string
+		codes   []string
+	}{
+		{name: "Will keep the other quote inside a string", source: `"it's"`, literal: "it's"},
+		{name: "Will keep double quotes inside single quotes", source: `'say "hi"'`, literal: `say "hi"`},
+		{name: "Will decode standard escapes", source: `"a\tb\nc\\d\"e\'f\r\0"`, literal: "a\tb\nc\\d\"e'f\r\x00"},
+		{name: "Will decode unicode escapes", source: `"\u{41}\u{e9}\u{1F600}"`, literal: "Aé😀"},
+		{name: "Will keep raw strings verbatim", source: "`C:\\dir\\n\n\"x\"`", literal: "C:\\dir\\n\n\"x\""},
+		{name: "Will report unknown escapes and keep going", source: `"a\qb"`, literal: "ab", codes: []string{CodeInvalidEscape}},
+		{name: "Will report unicode escapes without braces", source: `"\u0041"`, literal: "0041", codes: []string{CodeInvalidEscape}},
+		{name: "Will report unicode escapes with too many digits", source: `"\u{1234567}"`, literal: "}", codes: []string{CodeInvalidEscape}},
+		{name: "Will report surrogate code points", source: `"\u{D800}"`, literal: "", codes: []string{CodeInvalidEscape}},
+		{name: "Will report escaped end of input as unterminated", source: `"abc\`, codes: []string{CodeUnterminatedString}},
+		{name: "Will report unterminated raw strings", source: "`abc", codes: []string{CodeUnterminatedString}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := NewScanner(tt.source)
+			s.Reporter = &diagnostics
+			tokens := s.ScanTokens()
+
+			var codes []string
+			for _, d := range diagnostics {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostic codes = %v, want %v", codes, tt.codes)
+			}
+			if tt.codes != nil && tt.codes[0] == CodeUnterminatedString {
+				if len(tokens) != 2 || tokens[0].Type != token.ILLEGAL || tokens[0].Lexeme != tt.source {
+					t.Errorf("ScanTokens() = %v, want one ILLEGAL token", tokens)
+				}
+				return
+			}
+			if len(tokens) != 2 || tokens[0].Type != token.STRING {
+				t.Fatalf("ScanTokens() = %v, want one STRING token", tokens)
+			}
+			if got := tokens[0].Literal; got != tt.literal {
+				t.Errorf("literal = %#v, want %q", got, tt.literal)
+			}
+			if got := tokens[0].Lexeme; got != tt.source {
+				t.Errorf("lexeme = %q, want %q", got, tt.source)
+			}
+		})
+	}
+}
+
+func TestScanner_escapeSpan(t *testing.T) {
+	diagnostics := error.List{}
+	s := NewScanner(`x = "ab\qc";`)
+	s.Reporter = &diagnostics
+	s.ScanTokens()
+	if len(diagnostics) != 1 {
+		t.Fatalf("Scanner.Reporter received %v, want one diagnostic", diagnostics)
+	}
+	if got, want := diagnostics[0].Span, span(7, 1, 8, 9, 1, 10); got != want {
+		t.Errorf("diagnostic span = %v, want %v", got, want)
+	}
+}
+
+func TestScanner_numberLiterals(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		lexemes []string
+		literal interface{}
+		codes   []string
+	}{
+		{name: "Will scan hexadecimal literals", source: "0xFF_ff", lexemes: []string{"0xFF_ff"}, literal: int64(0xFFFF)},
+		{name: "Will scan octal literals", source: "0o755", lexemes: []string{"0o755"}, literal: int64(0755)},
+		{name: "Will scan binary literals", source: "0B1010_0101", lexemes: []string{"0B1010_0101"}, literal: int64(0xA5)},
+		{name: "Will remove digit separators", source: "1_000_000", lexemes: []string{"1_000_000"}, literal: int64(1000000)},
+		{name: "Will scan exponents", source: "1e-9", lexemes: []string{"1e-9"}, literal: 1e-9},
+		{name: "Will scan fractions with exponents", source: "6.022_140E+23", lexemes: []string{"6.022_140E+23"}, literal: 6.022140e+23},
+		{name: "Will leave property access after a number", source: "1.abs", lexemes: []string{"1", ".", "abs"}, literal: int64(1)},
+		{name: "Will reject a trailing decimal point", source: "1.;", lexemes: []string{"1.", ";"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject prefixes without digits", source: "0x;", lexemes: []string{"0x", ";"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject exponents without digits", source: "2e+", lexemes: []string{"2e+"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject digits outside the base", source: "0b102", lexemes: []string{"0b102"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject doubled separators", source: "1__0", lexemes: []string{"1__0"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject trailing separators", source: "10_", lexemes: []string{"10_"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will reject invalid suffixes", source: "12abc", lexemes: []string{"12abc"}, codes: []string{CodeMalformedNumber}},
+		{name: "Will keep leading zeros decimal", source: "0755", lexemes: []string{"0755"}, literal: int64(755)},
+		{name: "Will scan the largest integer", source: "9223372036854775807", lexemes: []string{"9223372036854775807"}, literal: int64(9223372036854775807)},
+		{name: "Will reject integers that overflow", source: "9223372036854775808", lexemes: []string{"9223372036854775808"}, codes: []string{CodeNumberRange}},
+		{name: "Will reject hexadecimal integers that overflow", source: "0x1_0000_0000_0000_0000", lexemes: []string{"0x1_0000_0000_0000_0000"}, codes: []string{CodeNumberRange}},
+		{name: "Will reject floats that overflow", source: "1e400", lexemes: []string{"1e400"}, codes: []string{CodeNumberRange}},
+		{name: "Will reject floats that underflow to zero", source: "1e-400", lexemes: []string{"1e-400"}, codes: []string{CodeNumberRange}},
+		{name: "Will warn about floats that lose precision", source: "1e-320", lexemes: []string{"1e-320"}, literal: 1e-320, codes: []string{CodeNumberPrecision}},
+		{name: "Will scan the smallest normal float", source: "2.2250738585072014e-308", lexemes: []string{"2.2250738585072014e-308"}, literal: 2.2250738585072014e-308},
+		{name: "Will keep zero floats", source: "0.0e-400", lexemes: []string{"0.0e-400"}, literal: 0.0},
+		{name: "Will not treat non-ASCII digits as numbers", source: "1٣", lexemes: []string{"1", "٣"}, literal: int64(1), codes: []string{CodeUnexpectedCharacter}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := NewScanner(tt.source)
+			s.Reporter = &diagnostics
+			tokens := s.ScanTokens()
+
+			var codes []string
+			for _, d := range diagnostics {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostic codes = %v, want %v", codes, tt.codes)
+			}
+			var lexemes []string
+			for _, tok := range tokens[:len(tokens)-1] {
+				lexemes = append(lexemes, tok.Lexeme)
+			}
+			if !reflect.DeepEqual(lexemes, tt.lexemes) {
+				t.Errorf("lexemes = %q, want %q", lexemes, tt.lexemes)
+			}
+			want := token.NUMBER
+			if tt.codes != nil && (tt.codes[0] == CodeMalformedNumber || tt.codes[0] == CodeNumberRange) {
+				want = token.ILLEGAL
+			}
+			if tokens[0].Type != want || tokens[0].Literal != tt.literal {
+				t.Errorf("first token = %v, want %v with literal %#v", tokens[0], want, tt.literal)
+			}
+		})
+	}
+}
+
+func TestScanner_keywordLiterals(t *testing.T) {
+	tokens := NewScanner("true false nil").ScanTokens()
+	want := []interface{}{true, false, nil, nil}
+	for i, tok := range tokens {
+		if tok.Literal != want[i] {
+			t.Errorf("token %v literal = %#v, want %#v", tok.Lexeme, tok.Literal, want[i])
+		}
+	}
+}
+
+func TestScanner_comments(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		types  []token.TokenType
+		codes  []string
+	}{
+		{name: "Will scan division", source: "a / b", types: []token.TokenType{token.IDENTIFIER, token.SLASH, token.IDENTIFIER}},
+		{name: "Will skip line comments", source: "a // b / c\nd", types: []token.TokenType{token.IDENTIFIER, token.IDENTIFIER}},
+		{name: "Will skip block comments", source: "a /* b \n c */ d", types: []token.TokenType{token.IDENTIFIER, token.IDENTIFIER}},
+		{name: "Will skip nested block comments", source: "a /* b /* c */ d */ e", types: []token.TokenType{token.IDENTIFIER, token.IDENTIFIER}},
+		{name: "Will not nest on */ alone", source: "a /* b */ */", types: []token.TokenType{token.IDENTIFIER, token.STAR, token.SLASH}},
+		{name: "Will report unterminated block comments", source: "a /* b /* c */", types: []token.TokenType{token.IDENTIFIER}, codes: []string{CodeUnterminatedComment}},
+		{name: "Will scan doc comments", source: "/// Adds.\nfun add() {}", types: []token.TokenType{token.DOC_COMMENT, token.FUN, token.IDENTIFIER, token.LEFT_PAREN, token.RIGHT_PAREN, token.LEFT_BRACE, token.RIGHT_BRACE}},
+		{name: "Will skip four slash comments", source: "//// ----\na", types: []token.TokenType{token.IDENTIFIER}},
+		{name: "Will report a backslash", source: "\\", types: []token.TokenType{token.ILLEGAL}, codes: []string{CodeUnexpectedCharacter}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := NewScanner(tt.source)
+			s.Reporter = &diagnostics
+			tokens := s.ScanTokens()
+
+			var codes []string
+			for _, d := range diagnostics {
+				codes = append(codes, d.Code)
+			}
+			if !reflect.DeepEqual(codes, tt.codes) {
+				t.Errorf("diagnostic codes = %v, want %v", codes, tt.codes)
+			}
+			var types []token.TokenType
+			for _, tok := range tokens[:len(tokens)-1] {
+				types = append(types, tok.Type)
+			}
+			if !reflect.DeepEqual(types, tt.types) {
+				t.Errorf("token types = %v, want %v", types, tt.types)
+			}
+		})
+	}
+}
+
+func TestScanner_docComment(t *testing.T) {
+	tokens := NewScanner("  /// Returns the sum.\r\nfun").ScanTokens()
+	want := token.Token{
+		Type:    token.DOC_COMMENT,
+		Lexeme:  "/// Returns the sum.",
+		Literal: "Returns the sum.",
+		Line:    1,
+		Span:    span(2, 1, 3, 22, 1, 23),
+	}
+	if !reflect.DeepEqual(tokens[0], want) {
+		t.Errorf("doc comment token = %v, want %v", tokens[0], want)
+	}
+}
+
+func TestScanner_trivia(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{name: "Empty source", source: ""},
+		{name: "Only trivia", source: "  // nothing\n\n"},
+		{name: "Declarations with comments", source: "var a = 1; // one\n\t/* two\n */ print a;\r\n/// doc\nfun"},
+		{name: "Unexpected characters", source: "a @# b"},
+		{name: "Unterminated string", source: "a 'b\nc"},
+		{name: "Unterminated comment", source: "a /* b"},
+		{name: "Byte order mark and shebang", source: "\uFEFF#!/usr/bin/env xolog\r\nprint 1;\r\n"},
+		{name: "Mixed line endings", source: "a\r\nb\rc\n\r\rd"},
+		{name: "Invalid UTF-8", source: "a \xff\xfe 'b\xc0'"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewScanner(tt.source)
+			s.Reporter = &error.List{}
+			s.Mode = ScanTrivia
+			tokens := s.ScanTokens()
+			if got := token.Reconstruct(tokens); got != tt.source {
+				t.Errorf("token.Reconstruct(ScanTokens()) = %q, want %q", got, tt.source)
+			}
+
+			r := NewReaderScanner("", iotest.OneByteReader(strings.NewReader(tt.source)))
+			r.Reporter = &error.List{}
+			r.Mode = ScanTrivia
+			var got []token.Token
+			for tok := r.Next(); ; tok = r.Next() {
+				got = append(got, tok)
+				if tok.Type == token.EOF {
+					break
+				}
+			}
+			if !reflect.DeepEqual(got, tokens) {
+				t.Errorf("Scanner.Next() = %v, want %v", got, tokens)
+			}
+		})
+	}
+}
+
+func TestScanner_triviaAttachment(t *testing.T) {
+	s := NewScanner("a  // c\n  b\n")
+	s.Mode = ScanTrivia
+	tokens := s.ScanTokens()
+	want := [][2][]token.Trivia{
+		{nil, {{Kind: token.WHITESPACE, Text: "  "}, {Kind: token.COMMENT, Text: "// c"}, {Kind: token.NEWLINE, Text: "\n"}}},
+		{{{Kind: token.WHITESPACE, Text: "  "}}, {{Kind: token.NEWLINE, Text: "\n"}}},
+		{nil, nil},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("ScanTokens() returned %d tokens, want %d", len(tokens), len(want))
+	}
+	for i, tok := range tokens {
+		if !reflect.DeepEqual(tok.LeadingTrivia, want[i][0]) || !reflect.DeepEqual(tok.TrailingTrivia, want[i][1]) {
+			t.Errorf("token %d trivia = %v %v, want %v %v", i, tok.LeadingTrivia, tok.TrailingTrivia, want[i][0], want[i][1])
+		}
+	}
+}
+
+func TestScanner_illegal(t *testing.T) {
+	diagnostics := error.List{}
+	s := NewScanner("a @#$ b \\\n§")
+	s.Reporter = &diagnostics
+	tokens := s.ScanTokens()
+
+	var illegal []string
+	for _, tok := range tokens {
+		if tok.Type == token.ILLEGAL {
+			illegal = append(illegal, tok.Lexeme)
+		}
+	}
+	if want := []string{"@#$", "\\", "§"}; !reflect.DeepEqual(illegal, want) {
+		t.Errorf("ILLEGAL lexemes = %q, want %q", illegal, want)
+	}
+	var messages []string
+	for _, d := range diagnostics {
+		messages = append(messages, d.Message)
+	}
+	want := []string{"Unexpected characters: @#$", "Unexpected character: \\", "Unexpected character: §"}
+	if !reflect.DeepEqual(messages, want) {
+		t.Errorf("diagnostic messages = %q, want %q", messages, want)
+	}
+	if got, want := diagnostics[0].Span, span(2, 1, 3, 5, 1, 6); got != want {
+		t.Errorf("diagnostic span = %v, want %v", got, want)
+	}
+}
+
+func TestScanner_MaxErrors(t *testing.T) {
+	diagnostics := error.List{}
+	s := NewScanner("@ a \\ b $ c 'd")
+	s.Reporter = &diagnostics
+	s.MaxErrors = 2
+	tokens := s.ScanTokens()
+
+	var codes []string
+	for _, d := range diagnostics {
+		codes = append(codes, d.Code)
+	}
+	if want := []string{CodeUnexpectedCharacter, CodeUnexpectedCharacter, error.CodeTooManyErrors}; !reflect.DeepEqual(codes, want) {
+		t.Errorf("diagnostic codes = %v, want %v", codes, want)
+	}
+	if diagnostics[2].Severity != error.SeverityNote {
+		t.Errorf("last diagnostic = %v, want a note", diagnostics[2])
+	}
+	if len(tokens) != 8 {
+		t.Errorf("ScanTokens() = %v, want scanning to carry on past the cap", tokens)
+	}
+	if !s.HadError {
+		t.Errorf("Scanner.HadError = false, want true")
+	}
+}
+
+func TestScanner_operators(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		types  []token.TokenType
+	}{
+		{name: "Will scan arithmetic operators", source: "% ** %= += -= *= /=", types: []token.TokenType{token.PERCENT, token.STAR_STAR, token.PERCENT_EQUAL, token.PLUS_EQUAL, token.MINUS_EQUAL, token.STAR_EQUAL, token.SLASH_EQUAL}},
+		{name: "Will scan increments", source: "a++ --b", types: []token.TokenType{token.IDENTIFIER, token.PLUS_PLUS, token.MINUS_MINUS, token.IDENTIFIER}},
+		{name: "Will scan the ternary operator", source: "a ? b : c", types: []token.TokenType{token.IDENTIFIER, token.QUESTION, token.IDENTIFIER, token.COLON, token.IDENTIFIER}},
+		{name: "Will scan arrows", source: "-> =>", types: []token.TokenType{token.ARROW, token.FAT_ARROW}},
+		{name: "Will scan ranges after numbers", source: "1..2 1...2", types: []token.TokenType{token.NUMBER, token.DOT_DOT, token.NUMBER, token.NUMBER, token.DOT_DOT_DOT, token.NUMBER}},
+		{name: "Will scan the pipe operator", source: "a |> b", types: []token.TokenType{token.IDENTIFIER, token.PIPE_GREATER, token.IDENTIFIER}},
+		{name: "Will scan bitwise operators", source: "& | ^ ~ << >>", types: []token.TokenType{token.AMPERSAND, token.PIPE, token.CARET, token.TILDE, token.LESS_LESS, token.GREATER_GREATER}},
+		{name: "Will munch the longest operator", source: "***=....---", types: []token.TokenType{token.STAR_STAR, token.STAR_EQUAL, token.DOT_DOT_DOT, token.DOT, token.MINUS_MINUS, token.MINUS}},
+		{name: "Will keep comparisons", source: "<= >= == != < >", types: []token.TokenType{token.LESS_EQUAL, token.GREATER_EQUAL, token.EQUAL_EQUAL, token.BANG_EQUAL, token.LESS, token.GREATER}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := NewScanner(tt.source)
+			s.Reporter = &diagnostics
+			tokens := s.ScanTokens()
+			if len(diagnostics) > 0 {
+				t.Errorf("Scanner.Reporter received %v, want none", diagnostics)
+			}
+			var types []token.TokenType
+			for _, tok := range tokens[:len(tokens)-1] {
+				types = append(types, tok.Type)
+			}
+			if !reflect.DeepEqual(types, tt.types) {
+				t.Errorf("token types = %v, want %v", types, tt.types)
+			}
+		})
+	}
+}
+
+func TestScanner_encoding(t *testing.T) {
+	type want struct {
+		types []token.TokenType
+		lines []int
+		codes []string
+	}
+	tests := []struct {
+		name   string
+		source string
+		want   want
+	}{
+		{name: "Will skip a byte order mark", source: "\uFEFFvar a", want: want{types: []token.TokenType{token.VAR, token.IDENTIFIER}, lines: []int{1, 1}}},
+		{name: "Will skip a shebang line", source: "#!/usr/bin/env xolog\nprint 1;", want: want{types: []token.TokenType{token.PRINT, token.NUMBER, token.SEMICOLON}, lines: []int{2, 2, 2}}},
+		{name: "Will skip a shebang after a byte order mark", source: "\uFEFF#! xolog\na", want: want{types: []token.TokenType{token.IDENTIFIER}, lines: []int{2}}},
+		{name: "Will only skip a shebang on the first line", source: "a\n#!b", want: want{types: []token.TokenType{token.IDENTIFIER, token.ILLEGAL, token.BANG, token.IDENTIFIER}, lines: []int{1, 2, 2, 2}, codes: []string{CodeUnexpectedCharacter}}},
+		{name: "Will count CRLF, CR and LF lines alike", source: "a\r\nb\rc\nd\r\n\re", want: want{types: []token.TokenType{token.IDENTIFIER, token.IDENTIFIER, token.IDENTIFIER, token.IDENTIFIER, token.IDENTIFIER}, lines: []int{1, 2, 3, 4, 6}}},
+		{name: "Will count lines in strings", source: "'a\r\nb\rc' d", want: want{types: []token.TokenType{token.STRING, token.IDENTIFIER}, lines: []int{1, 3}}},
+		{name: "Will report invalid UTF-8 once", source: "a \xff\xfe b", want: want{types: []token.TokenType{token.IDENTIFIER, token.ILLEGAL, token.IDENTIFIER}, lines: []int{1, 1, 1}, codes: []string{CodeInvalidUTF8, CodeInvalidUTF8}}},
+		{name: "Will report invalid UTF-8 in strings", source: "'a\xc0'", want: want{types: []token.TokenType{token.STRING}, lines: []int{1}, codes: []string{CodeInvalidUTF8}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			diagnostics := error.List{}
+			s := NewScanner(tt.source)
+			s.Reporter = &diagnostics
+			tokens := s.ScanTokens()
+
+			var got want
+			for _, tok := range tokens[:len(tokens)-1] {
+				got.types = append(got.types, tok.Type)
+				got.lines = append(got.lines, tok.Span.Start.Line)
+			}
+			for _, d := range diagnostics {
+				got.codes = append(got.codes, d.Code)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ScanTokens() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestScanner_encodingSpans(t *testing.T) {
+	diagnostics := error.List{}
+	s := NewScanner("\uFEFFa\r\n 'b\xffc'")
+	s.Reporter = &diagnostics
+	tokens := s.ScanTokens()
+
+	if got, want := tokens[0].Span, span(3, 1, 1, 4, 1, 2); got != want {
+		t.Errorf("first token span = %v, want %v", got, want)
+	}
+	if got, want := tokens[1].Literal, "b\uFFFDc"; got != want {
+		t.Errorf("string literal = %q, want %q", got, want)
+	}
+	if len(diagnostics) != 1 || diagnostics[0].Span != span(9, 2, 4, 10, 2, 5) {
+		t.Errorf("Scanner.Reporter received %v, want one diagnostic at 2:4-2:5", diagnostics)
+	}
+}
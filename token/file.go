@@ -0,0 +1,188 @@
+package token
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+	"unicode/utf8"
+)
+
+// BOM is the UTF-8 byte order mark. At the start of a source it is skipped, and does not
+// take up a column: columns on the first line are counted from the end of it.
+const BOM = "\uFEFF"
+
+// Pos is a compact encoding of a source position within a FileSet.
+// It can be converted into a Position with FileSet.Position.
+type Pos int
+
+// NoPos is the zero value for Pos; there is no file and line information for it.
+const NoPos Pos = 0
+
+// IsValid reports whether the position is valid.
+func (p Pos) IsValid() bool {
+	return p != NoPos
+}
+
+// File is a handle for a source file registered with a FileSet.
+type File struct {
+	name   string
+	base   int
+	size   int
+	source []byte
+
+	mutex sync.Mutex
+	lines []int // byte offsets of the first character of each line
+}
+
+// Name returns the file name the file was registered with.
+func (f *File) Name() string {
+	return f.name
+}
+
+// Base returns the Pos value of the first byte in the file.
+func (f *File) Base() int {
+	return f.base
+}
+
+// Size returns the size of the file in bytes.
+func (f *File) Size() int {
+	return f.size
+}
+
+// Source returns the source text the file was registered with.
+func (f *File) Source() []byte {
+	return f.source
+}
+
+// LineCount returns the number of lines recorded so far.
+func (f *File) LineCount() int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	return len(f.lines)
+}
+
+// AddLine records the byte offset of the start of a new line.
+// Offsets that do not follow the last recorded line, or lie past the end of the file, are
+// ignored. A line may start at the end of the file, after a trailing newline.
+func (f *File) AddLine(offset int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if n := len(f.lines); (n == 0 || f.lines[n-1] < offset) && offset <= f.size {
+		f.lines = append(f.lines, offset)
+	}
+}
+
+// LineStart returns the byte offset of the first character of line, or -1 if unknown.
+func (f *File) LineStart(line int) int {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+	if line < 1 || line > len(f.lines) {
+		return -1
+	}
+	return f.lines[line-1]
+}
+
+// Pos returns the Pos value for the given byte offset.
+func (f *File) Pos(offset int) Pos {
+	if offset < 0 || offset > f.size {
+		panic("illegal file offset")
+	}
+	return Pos(f.base + offset)
+}
+
+// Offset returns the byte offset for the given Pos.
+func (f *File) Offset(p Pos) int {
+	if int(p) < f.base || int(p) > f.base+f.size {
+		panic("illegal Pos value")
+	}
+	return int(p) - f.base
+}
+
+// Line returns the line number for the given Pos.
+func (f *File) Line(p Pos) int {
+	return f.Position(p).Line
+}
+
+// Position returns the Position value for the given Pos.
+// Columns are counted in runes, matching the positions the scanner records.
+func (f *File) Position(p Pos) Position {
+	if !p.IsValid() {
+		return Position{}
+	}
+	offset := f.Offset(p)
+
+	f.mutex.Lock()
+	i := sort.Search(len(f.lines), func(i int) bool { return f.lines[i] > offset }) - 1
+	lineStart := 0
+	if i >= 0 {
+		lineStart = f.lines[i]
+	}
+	f.mutex.Unlock()
+
+	column := offset - lineStart + 1
+	if f.source != nil {
+		if lineStart == 0 && offset >= len(BOM) && bytes.HasPrefix(f.source, []byte(BOM)) {
+			lineStart = len(BOM)
+		}
+		column = utf8.RuneCount(f.source[lineStart:offset]) + 1
+	}
+	return Position{Filename: f.name, Offset: offset, Line: i + 1, Column: column}
+}
+
+// FileSet is a registry of source files. Each file is assigned a distinct
+// range of Pos values so that a single Pos identifies both file and offset.
+type FileSet struct {
+	mutex sync.RWMutex
+	base  int
+	files []*File
+}
+
+// NewFileSet returns a pointer to an empty FileSet.
+func NewFileSet() *FileSet {
+	return &FileSet{base: 1}
+}
+
+// AddFile registers source under filename, and returns the new File.
+func (s *FileSet) AddFile(filename string, source []byte) *File {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	f := &File{name: filename, base: s.base, size: len(source), source: source, lines: []int{0}}
+	// +1 so that the position just past the end of a file stays unique.
+	s.base += len(source) + 1
+	s.files = append(s.files, f)
+	return f
+}
+
+// File returns the file containing p, or nil if there is none.
+func (s *FileSet) File(p Pos) *File {
+	if !p.IsValid() {
+		return nil
+	}
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	i := sort.Search(len(s.files), func(i int) bool { return s.files[i].base > int(p) }) - 1
+	if i >= 0 && int(p) <= s.files[i].base+s.files[i].size {
+		return s.files[i]
+	}
+	return nil
+}
+
+// Lookup returns the most recently added file named filename, or nil if there is none.
+func (s *FileSet) Lookup(filename string) *File {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	for i := len(s.files) - 1; i >= 0; i-- {
+		if s.files[i].name == filename {
+			return s.files[i]
+		}
+	}
+	return nil
+}
+
+// Position converts p into a Position, or the zero Position if p is unknown.
+func (s *FileSet) Position(p Pos) Position {
+	if f := s.File(p); f != nil {
+		return f.Position(p)
+	}
+	return Position{}
+}
@@ -0,0 +1,105 @@
+package token
+
+import "testing"
+
+func TestFileSet_Position(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.xo", []byte("var a;\nvar größe;\n"))
+	a.AddLine(7)
+	a.AddLine(20)
+	b := fset.AddFile("b.xo", []byte("print 1;"))
+	c := fset.AddFile("c.xo", []byte("\uFEFFprint 1;"))
+
+	tests := []struct {
+		name string
+		pos  Pos
+		want Position
+	}{
+		{
+			name: "NoPos resolves to the zero Position.",
+			pos:  NoPos,
+			want: Position{},
+		},
+		{
+			name: "Start of first file.",
+			pos:  a.Pos(0),
+			want: Position{Filename: "a.xo", Offset: 0, Line: 1, Column: 1},
+		},
+		{
+			name: "Columns count runes after multi-byte characters.",
+			pos:  a.Pos(17),
+			want: Position{Filename: "a.xo", Offset: 17, Line: 2, Column: 9},
+		},
+		{
+			name: "End of first file stays within the first file.",
+			pos:  a.Pos(20),
+			want: Position{Filename: "a.xo", Offset: 20, Line: 3, Column: 1},
+		},
+		{
+			name: "Second file.",
+			pos:  b.Pos(6),
+			want: Position{Filename: "b.xo", Offset: 6, Line: 1, Column: 7},
+		},
+		{
+			name: "A byte order mark takes up no column.",
+			pos:  c.Pos(9),
+			want: Position{Filename: "c.xo", Offset: 9, Line: 1, Column: 7},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fset.Position(tt.pos); got != tt.want {
+				t.Errorf("FileSet.Position() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFileSet_File(t *testing.T) {
+	fset := NewFileSet()
+	a := fset.AddFile("a.xo", []byte("abc"))
+	b := fset.AddFile("b.xo", []byte(""))
+	c := fset.AddFile("c.xo", []byte("x"))
+
+	tests := []struct {
+		name string
+		pos  Pos
+		want *File
+	}{
+		{name: "NoPos has no file.", pos: NoPos, want: nil},
+		{name: "Offset in first file.", pos: a.Pos(2), want: a},
+		{name: "Empty file.", pos: b.Pos(0), want: b},
+		{name: "End of last file.", pos: c.Pos(1), want: c},
+		{name: "Past every file.", pos: c.Pos(1) + 1, want: nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fset.File(tt.pos); got != tt.want {
+				t.Errorf("FileSet.File() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFile_AddLine(t *testing.T) {
+	fset := NewFileSet()
+	f := fset.AddFile("a.xo", []byte("a\nb\nc"))
+	f.AddLine(2)
+	f.AddLine(2)
+	f.AddLine(1)
+	f.AddLine(4)
+	f.AddLine(6)
+	if got, want := f.LineCount(), 3; got != want {
+		t.Errorf("File.LineCount() = %v, want %v", got, want)
+	}
+	if got, want := f.LineStart(3), 4; got != want {
+		t.Errorf("File.LineStart() = %v, want %v", got, want)
+	}
+	if got, want := f.LineStart(4), -1; got != want {
+		t.Errorf("File.LineStart() = %v, want %v", got, want)
+	}
+	f.AddLine(5)
+	if got, want := f.LineStart(4), 5; got != want {
+		t.Errorf("File.LineStart() at the end of the file = %v, want %v", got, want)
+	}
+}
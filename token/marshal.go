@@ -0,0 +1,176 @@
+package token
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// tokenJSON is the JSON form of a Token. Types are encoded by name, and Pos is left out
+// since it is only meaningful within the FileSet that produced it.
+type tokenJSON struct {
+	Type           TokenType       `json:"type"`
+	Lexeme         string          `json:"lexeme"`
+	Literal        json.RawMessage `json:"literal"`
+	Line           int             `json:"line"`
+	Span           Span            `json:"span"`
+	LeadingTrivia  []Trivia        `json:"leading,omitempty"`
+	TrailingTrivia []Trivia        `json:"trailing,omitempty"`
+}
+
+// MarshalJSON encodes the token as an object. Float literals always carry a decimal point
+// or exponent, so that they decode as float64 rather than int64.
+func (t Token) MarshalJSON() ([]byte, error) {
+	literal, err := formatLiteral(t.Literal, quoteJSON, "null")
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(tokenJSON{
+		Type:           t.Type,
+		Lexeme:         t.Lexeme,
+		Literal:        json.RawMessage(literal),
+		Line:           t.Line,
+		Span:           t.Span,
+		LeadingTrivia:  t.LeadingTrivia,
+		TrailingTrivia: t.TrailingTrivia,
+	})
+}
+
+// UnmarshalJSON decodes a token encoded by MarshalJSON.
+func (t *Token) UnmarshalJSON(data []byte) error {
+	var v tokenJSON
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	literal, err := parseLiteral(strings.TrimSpace(string(v.Literal)), unquoteJSON, "null")
+	if err != nil {
+		return err
+	}
+	*t = Token{
+		Type:           v.Type,
+		Lexeme:         v.Lexeme,
+		Literal:        literal,
+		Line:           v.Line,
+		Span:           v.Span,
+		LeadingTrivia:  v.LeadingTrivia,
+		TrailingTrivia: v.TrailingTrivia,
+	}
+	return nil
+}
+
+// MarshalText encodes the token on one line as tab separated fields:
+//
+//	line:column-line:column	TYPE	"lexeme"	literal
+//
+// The lexeme and string literals are quoted, and a missing literal is written as nil.
+// File names, byte offsets and trivia are left out; use JSON to keep them.
+func (t Token) MarshalText() ([]byte, error) {
+	literal, err := FormatLiteral(t.Literal)
+	if err != nil {
+		return nil, err
+	}
+	name, err := t.Type.MarshalText()
+	if err != nil {
+		return nil, err
+	}
+	start, end := t.Span.Start, t.Span.End
+	return []byte(fmt.Sprintf("%d:%d-%d:%d\t%s\t%s\t%s", start.Line, start.Column, end.Line, end.Column, name, strconv.Quote(t.Lexeme), literal)), nil
+}
+
+// UnmarshalText decodes a token encoded by MarshalText. Line is taken from the end of the span.
+func (t *Token) UnmarshalText(text []byte) error {
+	fields := strings.Split(string(text), "\t")
+	if len(fields) != 4 {
+		return fmt.Errorf("malformed token %q: want 4 tab separated fields", text)
+	}
+	var span Span
+	if _, err := fmt.Sscanf(fields[0], "%d:%d-%d:%d", &span.Start.Line, &span.Start.Column, &span.End.Line, &span.End.Column); err != nil {
+		return fmt.Errorf("malformed token span %q: %v", fields[0], err)
+	}
+	tokenType, err := ParseTokenType(fields[1])
+	if err != nil {
+		return err
+	}
+	lexeme, err := strconv.Unquote(fields[2])
+	if err != nil {
+		return fmt.Errorf("malformed token lexeme %s: %v", fields[2], err)
+	}
+	literal, err := parseLiteral(fields[3], strconv.Unquote, "nil")
+	if err != nil {
+		return err
+	}
+	*t = Token{Type: tokenType, Lexeme: lexeme, Literal: literal, Line: span.End.Line, Span: span}
+	return nil
+}
+
+// FormatLiteral returns a literal value as it is written in the text encoding of a Token:
+// strings are quoted, floats always carry a decimal point or exponent, and nil is "nil".
+func FormatLiteral(v interface{}) (string, error) {
+	return formatLiteral(v, strconv.Quote, "nil")
+}
+
+// formatLiteral encodes a literal value, quoting strings with quote and writing nil as null.
+func formatLiteral(v interface{}, quote func(string) string, null string) (string, error) {
+	switch v := v.(type) {
+	case nil:
+		return null, nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case float64:
+		if math.IsInf(v, 0) || math.IsNaN(v) {
+			return "", fmt.Errorf("unsupported literal value %v", v)
+		}
+		text := strconv.FormatFloat(v, 'g', -1, 64)
+		if !strings.ContainsAny(text, ".e") {
+			text += ".0"
+		}
+		return text, nil
+	case string:
+		return quote(v), nil
+	}
+	return "", fmt.Errorf("unsupported literal type %T", v)
+}
+
+// parseLiteral decodes a literal value encoded by formatLiteral.
+func parseLiteral(text string, unquote func(string) (string, error), null string) (interface{}, error) {
+	switch {
+	case text == null || text == "":
+		return nil, nil
+	case text == "true":
+		return true, nil
+	case text == "false":
+		return false, nil
+	case strings.HasPrefix(text, `"`):
+		value, err := unquote(text)
+		if err != nil {
+			return nil, fmt.Errorf("malformed string literal %s: %v", text, err)
+		}
+		return value, nil
+	case strings.ContainsAny(text, ".eE"):
+		value, err := strconv.ParseFloat(text, 64)
+		if err != nil {
+			return nil, fmt.Errorf("malformed float literal %s: %v", text, err)
+		}
+		return value, nil
+	}
+	value, err := strconv.ParseInt(text, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("malformed integer literal %s: %v", text, err)
+	}
+	return value, nil
+}
+
+func quoteJSON(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func unquoteJSON(s string) (string, error) {
+	var v string
+	err := json.Unmarshal([]byte(s), &v)
+	return v, err
+}
@@ -0,0 +1,95 @@
+package token
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestToken_MarshalJSON(t *testing.T) {
+	tests := []struct {
+		name  string
+		token Token
+		want  string
+	}{
+		{
+			name:  "Will encode the type by name.",
+			token: Token{Type: LEFT_PAREN, Lexeme: "(", Line: 1, Span: Span{Start: Position{Line: 1, Column: 1}, End: Position{Offset: 1, Line: 1, Column: 2}}},
+			want:  `{"type":"LEFT_PAREN","lexeme":"(","literal":null,"line":1,"span":{"start":{"offset":0,"line":1,"column":1},"end":{"offset":1,"line":1,"column":2}}}`,
+		},
+		{
+			name:  "Will keep a decimal point on whole floats.",
+			token: Token{Type: NUMBER, Lexeme: "2.0", Literal: 2.0},
+			want:  `{"type":"NUMBER","lexeme":"2.0","literal":2.0,"line":0,"span":{"start":{"offset":0,"line":0,"column":0},"end":{"offset":0,"line":0,"column":0}}}`,
+		},
+		{
+			name:  "Will encode trivia.",
+			token: Token{Type: STRING, Lexeme: `"a"`, Literal: "a", TrailingTrivia: []Trivia{{Kind: NEWLINE, Text: "\n"}}},
+			want:  `{"type":"STRING","lexeme":"\"a\"","literal":"a","line":0,"span":{"start":{"offset":0,"line":0,"column":0},"end":{"offset":0,"line":0,"column":0}},"trailing":[{"kind":"NEWLINE","text":"\n"}]}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.token)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("json.Marshal() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestToken_roundTrip(t *testing.T) {
+	span := Span{Start: Position{Offset: 4, Line: 2, Column: 3}, End: Position{Offset: 9, Line: 2, Column: 8}}
+	tokens := []Token{
+		{Type: IDENTIFIER, Lexeme: "größe", Line: 2, Span: span},
+		{Type: STRING, Lexeme: "'a\tb'", Literal: "a\tb", Line: 2, Span: span},
+		{Type: NUMBER, Lexeme: "0xFF", Literal: int64(255), Line: 2, Span: span},
+		{Type: NUMBER, Lexeme: "1e21", Literal: 1e21, Line: 2, Span: span},
+		{Type: NUMBER, Lexeme: "3.0", Literal: 3.0, Line: 2, Span: span},
+		{Type: TRUE, Lexeme: "true", Literal: true, Line: 2, Span: span},
+		{Type: EOF, Lexeme: "\000", Line: 2, Span: span},
+	}
+	for _, want := range tokens {
+		t.Run(want.Lexeme, func(t *testing.T) {
+			data, err := json.Marshal(want)
+			if err != nil {
+				t.Fatalf("json.Marshal() error = %v", err)
+			}
+			var got Token
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("json.Unmarshal(%s) error = %v", data, err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("json round trip = %#v, want %#v", got, want)
+			}
+
+			text, err := want.MarshalText()
+			if err != nil {
+				t.Fatalf("Token.MarshalText() error = %v", err)
+			}
+			got = Token{}
+			if err := got.UnmarshalText(text); err != nil {
+				t.Fatalf("Token.UnmarshalText(%q) error = %v", text, err)
+			}
+			noOffsets := want
+			noOffsets.Span.Start.Offset, noOffsets.Span.End.Offset = 0, 0
+			if !reflect.DeepEqual(got, noOffsets) {
+				t.Errorf("text round trip = %#v, want %#v", got, noOffsets)
+			}
+		})
+	}
+}
+
+func TestToken_MarshalText(t *testing.T) {
+	tok := Token{Type: STRING, Lexeme: `"a b"`, Literal: "a b", Line: 1, Span: Span{Start: Position{Line: 1, Column: 1}, End: Position{Offset: 5, Line: 1, Column: 6}}}
+	got, err := tok.MarshalText()
+	if err != nil {
+		t.Fatalf("Token.MarshalText() error = %v", err)
+	}
+	if want := "1:1-1:6\tSTRING\t\"\\\"a b\\\"\"\t\"a b\""; string(got) != want {
+		t.Errorf("Token.MarshalText() = %q, want %q", got, want)
+	}
+}
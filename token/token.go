@@ -1,6 +1,9 @@
 package token
 
-import "fmt"
+import (
+	"fmt"
+	"strings"
+)
 
 type TokenType int
 
@@ -17,6 +20,13 @@ const (
 	SEMICOLON
 	SLASH
 	STAR
+	PERCENT
+	QUESTION
+	COLON
+	AMPERSAND
+	PIPE
+	CARET
+	TILDE
 
 	// One or two character tokens.
 	BANG
@@ -28,6 +38,23 @@ const (
 	LESS
 	LESS_EQUAL
 
+	// Extended operators.
+	STAR_STAR
+	PLUS_EQUAL
+	MINUS_EQUAL
+	STAR_EQUAL
+	SLASH_EQUAL
+	PERCENT_EQUAL
+	PLUS_PLUS
+	MINUS_MINUS
+	ARROW
+	FAT_ARROW
+	DOT_DOT
+	DOT_DOT_DOT
+	PIPE_GREATER
+	LESS_LESS
+	GREATER_GREATER
+
 	// Literals.
 	IDENTIFIER
 	STRING
@@ -51,16 +78,247 @@ const (
 	VAR
 	WHILE
 
+	// Documentation.
+	DOC_COMMENT
+
+	// Input that could not be scanned; the lexeme holds the offending text.
+	ILLEGAL
+
 	EOF
 )
 
+var tokenNames = [...]string{
+	LEFT_PAREN:      "LEFT_PAREN",
+	RIGHT_PAREN:     "RIGHT_PAREN",
+	LEFT_BRACE:      "LEFT_BRACE",
+	RIGHT_BRACE:     "RIGHT_BRACE",
+	COMMA:           "COMMA",
+	DOT:             "DOT",
+	MINUS:           "MINUS",
+	PLUS:            "PLUS",
+	SEMICOLON:       "SEMICOLON",
+	SLASH:           "SLASH",
+	STAR:            "STAR",
+	PERCENT:         "PERCENT",
+	QUESTION:        "QUESTION",
+	COLON:           "COLON",
+	AMPERSAND:       "AMPERSAND",
+	PIPE:            "PIPE",
+	CARET:           "CARET",
+	TILDE:           "TILDE",
+	BANG:            "BANG",
+	BANG_EQUAL:      "BANG_EQUAL",
+	EQUAL:           "EQUAL",
+	EQUAL_EQUAL:     "EQUAL_EQUAL",
+	GREATER:         "GREATER",
+	GREATER_EQUAL:   "GREATER_EQUAL",
+	LESS:            "LESS",
+	LESS_EQUAL:      "LESS_EQUAL",
+	STAR_STAR:       "STAR_STAR",
+	PLUS_EQUAL:      "PLUS_EQUAL",
+	MINUS_EQUAL:     "MINUS_EQUAL",
+	STAR_EQUAL:      "STAR_EQUAL",
+	SLASH_EQUAL:     "SLASH_EQUAL",
+	PERCENT_EQUAL:   "PERCENT_EQUAL",
+	PLUS_PLUS:       "PLUS_PLUS",
+	MINUS_MINUS:     "MINUS_MINUS",
+	ARROW:           "ARROW",
+	FAT_ARROW:       "FAT_ARROW",
+	DOT_DOT:         "DOT_DOT",
+	DOT_DOT_DOT:     "DOT_DOT_DOT",
+	PIPE_GREATER:    "PIPE_GREATER",
+	LESS_LESS:       "LESS_LESS",
+	GREATER_GREATER: "GREATER_GREATER",
+	IDENTIFIER:      "IDENTIFIER",
+	STRING:          "STRING",
+	NUMBER:          "NUMBER",
+	AND:             "AND",
+	CLASS:           "CLASS",
+	ELSE:            "ELSE",
+	FALSE:           "FALSE",
+	FUN:             "FUN",
+	FOR:             "FOR",
+	IF:              "IF",
+	NIL:             "NIL",
+	OR:              "OR",
+	PRINT:           "PRINT",
+	RETURN:          "RETURN",
+	SUPER:           "SUPER",
+	THIS:            "THIS",
+	TRUE:            "TRUE",
+	VAR:             "VAR",
+	WHILE:           "WHILE",
+	DOC_COMMENT:     "DOC_COMMENT",
+	ILLEGAL:         "ILLEGAL",
+	EOF:             "EOF",
+}
+
+// String returns the canonical name of the token type.
+func (t TokenType) String() string {
+	if t >= 0 && int(t) < len(tokenNames) {
+		return tokenNames[t]
+	}
+	return fmt.Sprintf("TokenType(%d)", int(t))
+}
+
+// ParseTokenType returns the token type with the canonical name.
+func ParseTokenType(name string) (TokenType, error) {
+	for i, n := range tokenNames {
+		if n == name {
+			return TokenType(i), nil
+		}
+	}
+	return 0, fmt.Errorf("unknown token type %q", name)
+}
+
+// MarshalText encodes the token type by name, so that encodings survive new types being added.
+func (t TokenType) MarshalText() ([]byte, error) {
+	if t < 0 || int(t) >= len(tokenNames) {
+		return nil, fmt.Errorf("unknown token type %d", int(t))
+	}
+	return []byte(tokenNames[t]), nil
+}
+
+// UnmarshalText decodes a token type name.
+func (t *TokenType) UnmarshalText(text []byte) error {
+	tokenType, err := ParseTokenType(string(text))
+	if err != nil {
+		return err
+	}
+	*t = tokenType
+	return nil
+}
+
+// Position describes a single location within source text.
+type Position struct {
+	Filename string `json:"file,omitempty"` // file name, if any
+	Offset   int    `json:"offset"`         // byte offset, starting at 0
+	Line     int    `json:"line"`           // line number, starting at 1
+	Column   int    `json:"column"`         // column number in runes, starting at 1
+}
+
+// IsValid reports whether the position has been set.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// String returns the position as file:line:column, line:column, file, or "-" when unset.
+func (p Position) String() string {
+	s := p.Filename
+	if p.IsValid() {
+		if s != "" {
+			s += ":"
+		}
+		s += fmt.Sprintf("%d", p.Line)
+		if p.Column != 0 {
+			s += fmt.Sprintf(":%d", p.Column)
+		}
+	}
+	if s == "" {
+		s = "-"
+	}
+	return s
+}
+
+// Span is the half-open range of source text between Start and End.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// String returns the span as start-end, naming the file only once.
+func (s Span) String() string {
+	end := s.End
+	end.Filename = ""
+	return fmt.Sprintf("%s-%s", s.Start, end)
+}
+
+// TriviaKind classifies source text that carries no meaning for the grammar.
+type TriviaKind int
+
+const (
+	WHITESPACE TriviaKind = iota
+	NEWLINE
+	COMMENT
+)
+
+var triviaNames = [...]string{
+	WHITESPACE: "WHITESPACE",
+	NEWLINE:    "NEWLINE",
+	COMMENT:    "COMMENT",
+}
+
+func (k TriviaKind) String() string {
+	if k >= 0 && int(k) < len(triviaNames) {
+		return triviaNames[k]
+	}
+	return fmt.Sprintf("TriviaKind(%d)", int(k))
+}
+
+// MarshalText encodes the trivia kind by name.
+func (k TriviaKind) MarshalText() ([]byte, error) {
+	return []byte(k.String()), nil
+}
+
+// UnmarshalText decodes a trivia kind name.
+func (k *TriviaKind) UnmarshalText(text []byte) error {
+	for i, name := range triviaNames {
+		if name == string(text) {
+			*k = TriviaKind(i)
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown trivia kind %q", text)
+}
+
+// Trivia is a run of source text between tokens.
+type Trivia struct {
+	Kind TriviaKind `json:"kind"`
+	Text string     `json:"text"`
+}
+
 type Token struct {
 	Type    TokenType
 	Lexeme  string
-	Literal []rune
+	Literal interface{}
 	Line    int
+	Span    Span
+	Pos     Pos
+	// LeadingTrivia and TrailingTrivia are only filled in when the scanner keeps trivia.
+	// Trailing trivia runs up to and including the first newline after the token.
+	LeadingTrivia  []Trivia
+	TrailingTrivia []Trivia
+}
+
+func (t Token) String() string {
+	literal := t.Literal
+	if literal == nil {
+		literal = "nil"
+	}
+	return fmt.Sprintf("%s %s %v", t.Type, t.Lexeme, literal)
+}
+
+// Text returns the source text of the token with its trivia. The EOF token contributes
+// only its leading trivia.
+func (t Token) Text() string {
+	var b strings.Builder
+	for _, trivia := range t.LeadingTrivia {
+		b.WriteString(trivia.Text)
+	}
+	if t.Type != EOF {
+		b.WriteString(t.Lexeme)
+	}
+	for _, trivia := range t.TrailingTrivia {
+		b.WriteString(trivia.Text)
+	}
+	return b.String()
 }
 
-func (t *Token) String() string {
-	return fmt.Sprintf("%d %s %c", t.Type, t.Lexeme, t.Literal)
+// Reconstruct concatenates the text of tokens scanned with trivia, reproducing their source.
+func Reconstruct(tokens []Token) string {
+	var b strings.Builder
+	for _, t := range tokens {
+		b.WriteString(t.Text())
+	}
+	return b.String()
 }
@@ -6,7 +6,7 @@ func TestToken_toString(t *testing.T) {
 	type fields struct {
 		Type    TokenType
 		Lexeme  string
-		Literal []rune
+		Literal interface{}
 		Line    int
 	}
 	tests := []struct {
@@ -22,27 +22,27 @@ func TestToken_toString(t *testing.T) {
 				Literal: nil,
 				Line:    0,
 			},
-			want: "0 ( []",
+			want: "LEFT_PAREN ( nil",
 		},
 		{
 			name: "STRING token to string.",
 			fields: fields{
 				Type:    STRING,
 				Lexeme:  "Hello",
-				Literal: []rune("Hello"),
+				Literal: "Hello",
 				Line:    0,
 			},
-			want: "20 Hello [H e l l o]",
+			want: "STRING Hello Hello",
 		},
 		{
 			name: "Number token to string.",
 			fields: fields{
 				Type:    NUMBER,
 				Lexeme:  "123",
-				Literal: []rune("123"),
+				Literal: int64(123),
 				Line:    0,
 			},
-			want: "21 123 [1 2 3]",
+			want: "NUMBER 123 123",
 		},
 		{
 			name: "Number token to string.",
@@ -52,7 +52,7 @@ func TestToken_toString(t *testing.T) {
 				Literal: nil,
 				Line:    0,
 			},
-			want: "21 123 []",
+			want: "NUMBER 123 nil",
 		},
 		{
 			name: "LEFT_PAREN token to string.",
@@ -62,7 +62,7 @@ func TestToken_toString(t *testing.T) {
 				Literal: nil,
 				Line:    0,
 			},
-			want: "0 ( []",
+			want: "LEFT_PAREN ( nil",
 		},
 		{
 			name: "RIGHT_PAREN token to string.",
@@ -72,7 +72,7 @@ func TestToken_toString(t *testing.T) {
 				Literal: nil,
 				Line:    0,
 			},
-			want: "1 ) []",
+			want: "RIGHT_PAREN ) nil",
 		},
 	}
 	for _, tt := range tests {
@@ -89,3 +89,73 @@ func TestToken_toString(t *testing.T) {
 		})
 	}
 }
+
+func TestPosition_String(t *testing.T) {
+	tests := []struct {
+		name string
+		pos  Position
+		want string
+	}{
+		{name: "Unset position.", pos: Position{}, want: "-"},
+		{name: "Line and column.", pos: Position{Offset: 12, Line: 3, Column: 4}, want: "3:4"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.pos.String(); got != tt.want {
+				t.Errorf("Position.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSpan_String(t *testing.T) {
+	span := Span{
+		Start: Position{Offset: 0, Line: 1, Column: 1},
+		End:   Position{Offset: 5, Line: 1, Column: 6},
+	}
+	if got, want := span.String(), "1:1-1:6"; got != want {
+		t.Errorf("Span.String() = %v, want %v", got, want)
+	}
+}
+
+func TestReconstruct(t *testing.T) {
+	tokens := []Token{
+		{Type: IDENTIFIER, Lexeme: "a", LeadingTrivia: []Trivia{{Kind: COMMENT, Text: "/* x */"}}, TrailingTrivia: []Trivia{{Kind: NEWLINE, Text: "\n"}}},
+		{Type: EOF, Lexeme: "\000", LeadingTrivia: []Trivia{{Kind: WHITESPACE, Text: " "}}},
+	}
+	if got, want := Reconstruct(tokens), "/* x */a\n "; got != want {
+		t.Errorf("Reconstruct() = %q, want %q", got, want)
+	}
+}
+
+func TestTokenType_String(t *testing.T) {
+	tests := []struct {
+		tokenType TokenType
+		want      string
+	}{
+		{tokenType: LEFT_PAREN, want: "LEFT_PAREN"},
+		{tokenType: STRING, want: "STRING"},
+		{tokenType: DOT_DOT_DOT, want: "DOT_DOT_DOT"},
+		{tokenType: EOF, want: "EOF"},
+		{tokenType: EOF + 1, want: "TokenType(63)"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.want, func(t *testing.T) {
+			if got := tt.tokenType.String(); got != tt.want {
+				t.Errorf("TokenType.String() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTokenType(t *testing.T) {
+	for tokenType := LEFT_PAREN; tokenType <= EOF; tokenType++ {
+		got, err := ParseTokenType(tokenType.String())
+		if err != nil || got != tokenType {
+			t.Errorf("ParseTokenType(%q) = %v, %v, want %v", tokenType.String(), got, err, tokenType)
+		}
+	}
+	if _, err := ParseTokenType("NOPE"); err == nil {
+		t.Errorf("ParseTokenType(%q) returned no error", "NOPE")
+	}
+}
@@ -0,0 +1,281 @@
+package main
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"xolog/scanner"
+	"xolog/token"
+)
+
+// tokenWriter writes tokens in one of the formats of `xolog tokens`.
+type tokenWriter interface {
+	Write(tok token.Token) error
+	// Flush writes anything still buffered, and closes the document.
+	Flush() error
+}
+
+// newTokenWriter returns a tokenWriter for format, or nil if the format is unknown. When
+// files is set the table names the file of each token.
+func newTokenWriter(format string, w io.Writer, files bool) tokenWriter {
+	switch format {
+	case "table":
+		return eofWriter{newTableWriter(w, files)}
+	case "json":
+		return eofWriter{&jsonWriter{w: w}}
+	case "jsonl":
+		return eofWriter{&jsonlWriter{encoder: json.NewEncoder(w)}}
+	case "csv":
+		return eofWriter{newCSVWriter(w)}
+	}
+	return nil
+}
+
+// eofWriter writes the EOF token's lexeme, a NUL byte, empty, so no format prints it.
+type eofWriter struct {
+	tokenWriter
+}
+
+func (e eofWriter) Write(tok token.Token) error {
+	if tok.Type == token.EOF {
+		tok.Lexeme = ""
+	}
+	return e.tokenWriter.Write(tok)
+}
+
+// tableWriter aligns tokens into columns for reading in a terminal.
+type tableWriter struct {
+	w     *tabwriter.Writer
+	files bool
+}
+
+func newTableWriter(w io.Writer, files bool) *tableWriter {
+	t := &tableWriter{w: tabwriter.NewWriter(w, 0, 4, 2, ' ', 0), files: files}
+	fmt.Fprintln(t.w, "POSITION\tTYPE\tLEXEME\tLITERAL")
+	return t
+}
+
+func (t *tableWriter) Write(tok token.Token) error {
+	literal, err := token.FormatLiteral(tok.Literal)
+	if err != nil {
+		return err
+	}
+	start := tok.Span.Start
+	if !t.files {
+		start.Filename = ""
+	}
+	_, err = fmt.Fprintf(t.w, "%s\t%s\t%s\t%s\n", start, tok.Type, strconv.Quote(tok.Lexeme), literal)
+	return err
+}
+
+func (t *tableWriter) Flush() error {
+	return t.w.Flush()
+}
+
+// jsonWriter writes tokens as a single JSON array.
+type jsonWriter struct {
+	w     io.Writer
+	count int
+}
+
+func (j *jsonWriter) Write(tok token.Token) error {
+	data, err := json.Marshal(tok)
+	if err != nil {
+		return err
+	}
+	separator := ",\n  "
+	if j.count == 0 {
+		separator = "[\n  "
+	}
+	j.count++
+	_, err = fmt.Fprintf(j.w, "%s%s", separator, data)
+	return err
+}
+
+func (j *jsonWriter) Flush() error {
+	if j.count == 0 {
+		_, err := fmt.Fprintln(j.w, "[]")
+		return err
+	}
+	_, err := fmt.Fprintln(j.w, "\n]")
+	return err
+}
+
+// jsonlWriter writes one JSON object per line.
+type jsonlWriter struct {
+	encoder *json.Encoder
+}
+
+func (j *jsonlWriter) Write(tok token.Token) error {
+	return j.encoder.Encode(tok)
+}
+
+func (j *jsonlWriter) Flush() error {
+	return nil
+}
+
+// csvWriter writes a header row, then one row per token.
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func newCSVWriter(w io.Writer) *csvWriter {
+	c := &csvWriter{w: csv.NewWriter(w)}
+	c.w.Write([]string{"file", "line", "column", "end_line", "end_column", "type", "lexeme", "literal"})
+	return c
+}
+
+func (c *csvWriter) Write(tok token.Token) error {
+	literal, err := token.FormatLiteral(tok.Literal)
+	if err != nil {
+		return err
+	}
+	start, end := tok.Span.Start, tok.Span.End
+	return c.w.Write([]string{
+		start.Filename,
+		strconv.Itoa(start.Line),
+		strconv.Itoa(start.Column),
+		strconv.Itoa(end.Line),
+		strconv.Itoa(end.Column),
+		tok.Type.String(),
+		tok.Lexeme,
+		literal,
+	})
+}
+
+func (c *csvWriter) Flush() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// tokenFilter selects the tokens `xolog tokens` shows.
+type tokenFilter struct {
+	types    map[token.TokenType]bool // nil allows every type
+	fromLine int
+	toLine   int // zero means no upper bound
+}
+
+// parseTokenFilter builds a filter from a comma separated list of type names, and a line
+// range written as N, N-M, N- or -M.
+func parseTokenFilter(types string, lines string) (tokenFilter, error) {
+	var f tokenFilter
+	if types != "" {
+		f.types = map[token.TokenType]bool{}
+		for _, name := range strings.Split(types, ",") {
+			tokenType, err := token.ParseTokenType(strings.ToUpper(strings.TrimSpace(name)))
+			if err != nil {
+				return f, err
+			}
+			f.types[tokenType] = true
+		}
+	}
+	if lines != "" {
+		from, to := lines, lines
+		if i := strings.Index(lines, "-"); i >= 0 {
+			from, to = lines[:i], lines[i+1:]
+		}
+		var err error
+		if from != "" {
+			if f.fromLine, err = strconv.Atoi(from); err != nil {
+				return f, fmt.Errorf("invalid line range %q", lines)
+			}
+		}
+		if to != "" {
+			if f.toLine, err = strconv.Atoi(to); err != nil {
+				return f, fmt.Errorf("invalid line range %q", lines)
+			}
+		}
+	}
+	return f, nil
+}
+
+// Match reports whether tok passes the filter. Tokens are placed by the line they start on.
+func (f tokenFilter) Match(tok token.Token) bool {
+	if f.types != nil && !f.types[tok.Type] {
+		return false
+	}
+	line := tok.Span.Start.Line
+	return line >= f.fromLine && (f.toLine == 0 || line <= f.toLine)
+}
+
+// tokensCommand will implement `xolog tokens`, dumping the tokens of a script, or of stdin
+// when no script is given. Several scripts, or dir/... patterns, are scanned concurrently
+// and dumped in path order.
+func tokensCommand(args []string) {
+	flags := flag.NewFlagSet("tokens", flag.ExitOnError)
+	format := flags.String("format", "table", "output `format`: table, json, jsonl or csv")
+	types := flags.String("type", "", "only show tokens of the comma separated `types`, such as IDENTIFIER,NUMBER")
+	lines := flags.String("lines", "", "only show tokens starting on lines in `range`, such as 3-10, 3- or 7")
+	jobs := flags.Int("jobs", runtime.NumCPU(), "scan at most `n` files at once")
+	flags.Usage = func() {
+		fmt.Fprintln(os.Stderr, "Usage: xolog [flags] tokens [tokens flags] [script | dir/... ...]")
+		flags.PrintDefaults()
+	}
+	flags.Parse(args)
+	scripts := flags.Args()
+	single := len(scripts) <= 1 && !strings.HasSuffix(flags.Arg(0), "...")
+	filter, err := parseTokenFilter(*types, *lines)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(64)
+	}
+	out := bufio.NewWriter(os.Stdout)
+	w := newTokenWriter(*format, out, !single)
+	if w == nil {
+		fmt.Fprintf(os.Stderr, "unknown tokens format %q\n", *format)
+		flags.Usage()
+		os.Exit(64)
+	}
+
+	emit := func(tok token.Token) {
+		if !filter.Match(tok) {
+			return
+		}
+		if err := w.Write(tok); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+	switch path := flags.Arg(0); {
+	case !single:
+		paths, err := scanner.ExpandPaths(scripts)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		configure := func(s *scanner.Scanner) { s.MaxErrors = *maxErrors }
+		scanner.ScanFiles(paths, *jobs, configure, func(r scanner.Result) {
+			for _, tok := range r.Tokens {
+				emit(tok)
+			}
+			report(r.Diagnostics)
+		})
+	case path != "" && path != "-":
+		scanFile(path, emit)
+	default:
+		src, err := ioutil.ReadAll(os.Stdin)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		scanSource("<stdin>", string(src), emit)
+	}
+
+	err = w.Flush()
+	if err == nil {
+		err = out.Flush()
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
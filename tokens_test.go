@@ -0,0 +1,41 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"xolog/scanner"
+)
+
+func TestTokenWriter_eof(t *testing.T) {
+	tests := []struct {
+		format string
+		want   string
+	}{
+		{"table", `EOF   ""`},
+		{"json", `"lexeme":""`},
+		{"jsonl", `"lexeme":""`},
+		{"csv", `,EOF,,`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.format, func(t *testing.T) {
+			buf := bytes.Buffer{}
+			w := newTokenWriter(tt.format, &buf, false)
+			for _, tok := range scanner.NewScanner("").ScanTokens() {
+				if err := w.Write(tok); err != nil {
+					t.Fatalf("Write() error = %v", err)
+				}
+			}
+			if err := w.Flush(); err != nil {
+				t.Fatalf("Flush() error = %v", err)
+			}
+			got := buf.String()
+			if strings.ContainsRune(got, 0) || strings.Contains(got, `\u0000`) || strings.Contains(got, `\x00`) {
+				t.Errorf("%s output = %q, want no NUL lexeme", tt.format, got)
+			}
+			if !strings.Contains(got, tt.want) {
+				t.Errorf("%s output = %q, want it to contain %q", tt.format, got, tt.want)
+			}
+		})
+	}
+}
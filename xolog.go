@@ -2,14 +2,28 @@ package main
 
 import (
 	"bufio"
-	"bytes"
+	"flag"
 	"fmt"
+	"io"
 	"os"
+	"xolog/ast"
+	"xolog/error"
+	"xolog/parser"
 	"xolog/scanner"
+	"xolog/token"
 )
 
 var (
 	hadError bool
+	fset     = token.NewFileSet()
+	reporter error.Reporter
+
+	// collected holds diagnostics for the structured formats, which are written once at exit.
+	collected = error.List{}
+
+	diagnosticsFormat = flag.String("diagnostics-format", "text", "diagnostics `format`: text, json or sarif")
+	diagnosticsOutput = flag.String("diagnostics-output", "", "write diagnostics to `file` instead of stderr")
+	maxErrors         = flag.Int("max-errors", 0, "report at most `n` errors per file; 0 reports all")
 )
 
 func runPrompt() {
@@ -20,7 +34,7 @@ func runPrompt() {
 	fmt.Print("> ")
 
 	for scanner.Scan() {
-		run(scanner.Text())
+		run("<stdin>", scanner.Text())
 		fmt.Print("> ")
 	}
 
@@ -32,6 +46,40 @@ func runPrompt() {
 }
 
 func runFile(path string) {
+	var tokens []token.Token
+	scanFile(path, func(tok token.Token) { tokens = append(tokens, tok) })
+	printProgram(parse(tokens))
+}
+
+func run(filename string, src string) {
+	var tokens []token.Token
+	scanSource(filename, src, func(tok token.Token) { tokens = append(tokens, tok) })
+	printProgram(parse(tokens))
+}
+
+// printProgram will print the statements of program as S-expressions.
+func printProgram(program *ast.Program) {
+	if err := ast.WriteSexpr(os.Stdout, program); err != nil {
+		fmt.Println(err)
+		os.Exit(1)
+	}
+}
+
+// parse will parse tokens as a program, then report its diagnostics in source order.
+func parse(tokens []token.Token) *ast.Program {
+	diagnostics := error.List{}
+	p := parser.NewParser(tokens)
+	p.Reporter = &diagnostics
+	p.MaxErrors = *maxErrors
+	program := p.Parse()
+	diagnostics.Sort()
+	report(diagnostics)
+	return program
+}
+
+// scanFile will pass every token of the script at path to emit. Files are streamed, so
+// only diagnostics re-read the source to show excerpts.
+func scanFile(path string, emit func(token.Token)) {
 	file, err := os.Open(path)
 	if err != nil {
 		fmt.Println(err)
@@ -39,34 +87,115 @@ func runFile(path string) {
 	}
 	defer file.Close()
 
-	buf := bytes.Buffer{}
-	buf.ReadFrom(file)
-	content := buf.String()
+	scan(scanner.NewReaderScanner(path, bufio.NewReader(file)), emit)
+}
 
-	run(content)
-	if hadError {
-		os.Exit(65)
-	}
+// scanSource will register src with fset under filename, and pass every token to emit.
+func scanSource(filename string, src string, emit func(token.Token)) {
+	file := fset.AddFile(filename, []byte(src))
+	scan(scanner.NewFileScanner(file), emit)
+}
 
+// scan will pass every token from s to emit, then report its diagnostics in source order.
+func scan(s *scanner.Scanner, emit func(token.Token)) {
+	diagnostics := error.List{}
+	s.Reporter = &diagnostics
+	s.MaxErrors = *maxErrors
+	for {
+		tok := s.Next()
+		emit(tok)
+		if tok.Type == token.EOF {
+			break
+		}
+	}
+	diagnostics.Sort()
+	report(diagnostics)
 }
 
-func run(src string) {
-	s := scanner.NewScanner(src)
-	tokens := s.ScanTokens()
-	for _, token := range tokens {
-		fmt.Println(token)
+// report will pass diagnostics to the reporter, recording whether any are errors.
+func report(diagnostics error.List) {
+	for _, d := range diagnostics {
+		reporter.Report(d)
 	}
-	if s.HadError {
+	if diagnostics.HasErrors() {
 		hadError = true
 	}
 }
 
+// writeDiagnostics writes the collected diagnostics in the requested structured format.
+func writeDiagnostics(w io.Writer) {
+	switch *diagnosticsFormat {
+	case "json":
+		if err := error.WriteJSON(w, collected); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	case "sarif":
+		if err := error.WriteSARIF(w, "xolog", collected); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+	}
+}
+
+// commands maps subcommand names to the functions that run them with their arguments.
+var commands = map[string]func(args []string){
+	"tokens":    tokensCommand,
+	"highlight": highlightCommand,
+	"ast":       astCommand,
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "Usage: xolog [flags] [script]")
+	fmt.Fprintln(os.Stderr, "       xolog [flags] tokens [tokens flags] [script | dir/... ...]")
+	fmt.Fprintln(os.Stderr, "       xolog [flags] highlight [highlight flags] [script]")
+	fmt.Fprintln(os.Stderr, "       xolog [flags] ast [ast flags] [script]")
+	flag.PrintDefaults()
+}
+
 func main() {
-	if len(os.Args) > 2 {
-		fmt.Println("Usage: xolog [script]")
-	} else if len(os.Args) == 2 {
-		runFile(os.Args[1])
-	} else {
+	flag.Usage = usage
+	flag.Parse()
+	command, isCommand := commands[flag.Arg(0)]
+	if !isCommand && flag.NArg() > 1 {
+		usage()
+		os.Exit(64)
+	}
+
+	out := os.Stderr
+	if *diagnosticsOutput != "" {
+		f, err := os.Create(*diagnosticsOutput)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+		out = f
+	}
+	switch *diagnosticsFormat {
+	case "text":
+		reporter = error.NewRenderer(out, fset)
+	case "json", "sarif":
+		reporter = &collected
+	default:
+		fmt.Fprintf(os.Stderr, "unknown diagnostics format %q\n", *diagnosticsFormat)
+		usage()
+		os.Exit(64)
+	}
+
+	switch {
+	case isCommand:
+		command(flag.Args()[1:])
+	case flag.NArg() == 1:
+		runFile(flag.Arg(0))
+	default:
 		runPrompt()
 	}
+
+	writeDiagnostics(out)
+	if out != os.Stderr {
+		out.Close()
+	}
+	if hadError {
+		os.Exit(65)
+	}
 }